@@ -0,0 +1,55 @@
+package gap
+
+import "container/list"
+
+// fitnessCache is a least recently used cache of fitness values keyed by the
+// bytes of the solution.
+type fitnessCache struct {
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+
+	hits   uint64
+	misses uint64
+}
+
+type cacheEntry struct {
+	key     string
+	fitness float64
+}
+
+func newFitnessCache(size uint) *fitnessCache {
+	return &fitnessCache{
+		size:  int(size),
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// get returns the cached fitness of the solution s.
+func (c *fitnessCache) get(s []byte) (float64, bool) {
+	if el, ok := c.items[string(s)]; ok {
+		c.ll.MoveToFront(el)
+		return el.Value.(*cacheEntry).fitness, true
+	}
+	return 0, false
+}
+
+// add caches the fitness of the solution s, evicting the least recently used
+// value if the cache is full.
+func (c *fitnessCache) add(s []byte, fitness float64) {
+	if el, ok := c.items[string(s)]; ok {
+		c.ll.MoveToFront(el)
+		el.Value.(*cacheEntry).fitness = fitness
+		return
+	}
+
+	if c.ll.Len() >= c.size {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+	}
+
+	key := string(s)
+	c.items[key] = c.ll.PushFront(&cacheEntry{key: key, fitness: fitness})
+}
@@ -0,0 +1,275 @@
+package gap
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/stiganik/gap/combination"
+	"github.com/stiganik/gap/selection"
+	"github.com/stiganik/gap/solution"
+)
+
+// checkpointVersion has to be incremented whenever the fields of checkpoint
+// change. Decoding a checkpoint of a different layout would silently leave the
+// missing fields at their zero values, e.g. a zero seed, so checkpoints of
+// other versions are rejected instead.
+const (
+	checkpointMagic   = "gap-checkpoint"
+	checkpointVersion = uint(1)
+)
+
+// checkpointHeader is written at the start of every checkpoint file and
+// identifies the format of the data following it.
+type checkpointHeader struct {
+	Magic   string
+	Version uint
+}
+
+// checkpoint is the saved state of a run. A checkpoint is taken at the start
+// of a generation, before the solutions of the generation are evaluated.
+type checkpoint struct {
+	SolutionBitSize       uint
+	SolutionPoolSize      uint
+	Elitism               uint
+	Direction             solution.Direction
+	SelectionAlgorithm    selection.Algorithm
+	SelectionParams       selection.Params
+	CombinationAlgorithms []combination.Algorithm
+	Mode                  Mode
+	Offspring             uint
+	Replacement           Replacement
+	Seed                  int64
+
+	Generation  uint
+	ElapsedTime time.Duration
+	Evaluations uint64
+	Best        solution.Specimen
+	Worst       float64
+	WorstKnown  bool
+	Failures    uint64
+	Timeouts    uint64
+	CacheHits   uint64
+	CacheMisses uint64
+	History     History
+	HallOfFame  solution.Specimens
+	Specimens   solution.Specimens
+	Stagnation  stagnationState
+
+	SelectionState   uint64
+	CombinationState []uint64
+	ReplacementState uint64
+}
+
+func (r *run) checkpointDue() bool {
+	a := r.a
+	return a.CheckpointPath != "" && r.generation != r.resumed &&
+		r.generation%a.CheckpointInterval == 0
+}
+
+// checkpoint saves the state of the run into the checkpoint file of the
+// algorithm. The file is replaced atomically, so a crash while saving leaves
+// the previous checkpoint intact.
+func (r *run) checkpoint(g *Goal) error {
+	a := r.a
+	cp := checkpoint{
+		SolutionBitSize:       a.SolutionBitSize,
+		SolutionPoolSize:      a.SolutionPoolSize,
+		Elitism:               *a.Elitism,
+		Direction:             a.Direction,
+		SelectionAlgorithm:    a.SelectionAlgorithm,
+		SelectionParams:       a.SelectionParams,
+		CombinationAlgorithms: a.CombinationAlgorithms,
+		Mode:                  a.Mode,
+		Offspring:             a.Offspring,
+		Replacement:           a.Replacement,
+		Seed:                  r.seed,
+		Generation:            r.generation,
+		ElapsedTime:           r.elapsedTime(),
+		Evaluations:           r.evaluations,
+		Best:                  r.best,
+		Worst:                 r.worst,
+		WorstKnown:            r.worstKnown,
+		Failures:              r.failures,
+		Timeouts:              r.timeouts,
+		History:               r.history,
+		HallOfFame:            r.hof.copies(),
+		Specimens:             r.pools[r.cur].Specimens,
+		SelectionState:        r.selSrc.State(),
+		ReplacementState:      r.rndSrc.State(),
+	}
+	if r.cache != nil {
+		cp.CacheHits = r.cache.hits
+		cp.CacheMisses = r.cache.misses
+	}
+	if g.stagnation != nil {
+		cp.Stagnation = g.stagnation.state()
+	}
+	for _, src := range r.combSrcs {
+		cp.CombinationState = append(cp.CombinationState, src.State())
+	}
+
+	tmp := a.CheckpointPath + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	enc := gob.NewEncoder(f)
+	if err = enc.Encode(checkpointHeader{checkpointMagic, checkpointVersion}); err == nil {
+		err = enc.Encode(cp)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("Failed to write checkpoint: %w", err)
+	}
+
+	return os.Rename(tmp, a.CheckpointPath)
+}
+
+func readCheckpoint(path string) (cp checkpoint, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	dec := gob.NewDecoder(f)
+
+	var hdr checkpointHeader
+	if err = dec.Decode(&hdr); err != nil {
+		return cp, fmt.Errorf("Failed to read checkpoint: %w", err)
+	}
+	if hdr.Magic != checkpointMagic {
+		return cp, fmt.Errorf("Not a checkpoint file: %s", path)
+	}
+	if hdr.Version != checkpointVersion {
+		return cp, fmt.Errorf("Unsupported checkpoint version: %d", hdr.Version)
+	}
+
+	if err = dec.Decode(&cp); err != nil {
+		return cp, fmt.Errorf("Failed to read checkpoint: %w", err)
+	}
+	return
+}
+
+// configure overwrites the settings of the algorithm that have to match the
+// run the checkpoint was taken from.
+func (cp *checkpoint) configure(a *Algorithm) {
+	elitism := cp.Elitism
+	a.SolutionBitSize = cp.SolutionBitSize
+	a.SolutionPoolSize = cp.SolutionPoolSize
+	a.Elitism = &elitism
+	a.Direction = cp.Direction
+	a.SelectionAlgorithm = cp.SelectionAlgorithm
+	// Functions are not saved in the checkpoint, the ones of the algorithm
+	// are kept.
+	fn := a.SelectionParams.TemperatureFn
+	a.SelectionParams = cp.SelectionParams
+	a.SelectionParams.TemperatureFn = fn
+	a.CombinationAlgorithms = cp.CombinationAlgorithms
+	a.Mode = cp.Mode
+	a.Offspring = cp.Offspring
+	a.Replacement = cp.Replacement
+}
+
+// restore restores the state of a run created with the configuration of the
+// checkpoint.
+func (cp *checkpoint) restore(r *run) error {
+	pool := r.pools[r.cur]
+	if len(cp.Specimens) != len(pool.Specimens) {
+		return fmt.Errorf("Checkpoint pool size mismatch: %d", len(cp.Specimens))
+	}
+	for i := range cp.Specimens {
+		if uint(len(cp.Specimens[i].Buf)) != pool.SpecimenByteSize {
+			return fmt.Errorf("Checkpoint solution size mismatch: %d", len(cp.Specimens[i].Buf))
+		}
+		pool.Specimens[i].Copy(cp.Specimens[i])
+	}
+	if err := pool.CheckPadding(); err != nil {
+		return fmt.Errorf("Checkpoint: %w", err)
+	}
+	if len(cp.CombinationState) != len(r.combSrcs) {
+		return fmt.Errorf("Checkpoint combination state mismatch")
+	}
+
+	r.generation = cp.Generation
+	r.elapsed = cp.ElapsedTime
+	r.evaluations = cp.Evaluations
+	r.improved = cp.Generation > 0
+	if r.improved {
+		r.best.Copy(cp.Best)
+	}
+	r.worst = cp.Worst
+	r.worstKnown = cp.WorstKnown
+	r.failures = cp.Failures
+	r.timeouts = cp.Timeouts
+	if r.cache != nil {
+		r.cache.hits = cp.CacheHits
+		r.cache.misses = cp.CacheMisses
+	}
+	r.history = cp.History
+	for _, s := range cp.HallOfFame {
+		if uint(len(s.Buf)) == pool.SpecimenByteSize {
+			r.hof.offer(s)
+		}
+	}
+
+	r.selSrc.SetState(cp.SelectionState)
+	r.rndSrc.SetState(cp.ReplacementState)
+	for i, src := range r.combSrcs {
+		src.SetState(cp.CombinationState[i])
+	}
+	return nil
+}
+
+// Resume continues a run of the algorithm from the checkpoint file at path.
+// The solution size, pool size, elitism, direction, mode, selection and
+// combination settings of the algorithm are overwritten with the ones saved in
+// the checkpoint, the rest of the settings, e.g. the fitness function, are used as
+// is. The goal is checked against the totals of the whole run, e.g. the TIME
+// goal includes the time elapsed before the checkpoint was taken.
+func (a *Algorithm) Resume(path string, g Goal) (Result, error) {
+	return a.ResumeContext(context.Background(), path, g)
+}
+
+// ResumeContext is like Resume, but stops the algorithm once ctx is done as
+// described in RunContext.
+func (a *Algorithm) ResumeContext(ctx context.Context, path string, g Goal) (Result, error) {
+	cp, err := readCheckpoint(path)
+	if err != nil {
+		return Result{}, err
+	}
+
+	cp.configure(a)
+	if err = a.check(); err != nil {
+		return Result{}, err
+	}
+
+	r, err := a.newRun(cp.Seed)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if err = cp.restore(r); err != nil {
+		return Result{}, err
+	}
+
+	if err = g.init(ctx, a.Direction, r.elapsed); err != nil {
+		return Result{}, err
+	}
+	if g.stagnation != nil {
+		g.stagnation.restore(cp.Stagnation)
+	}
+	defer g.finalize()
+
+	return r.loop(&g)
+}
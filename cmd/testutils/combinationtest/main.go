@@ -41,7 +41,7 @@ func main() {
 	for _, algo := range algos {
 		reset(pool, poolResult)
 
-		comb, err := combination.New(algo, 0)
+		comb, err := combination.New(algo, combination.Options{})
 		if err != nil {
 			fmt.Println("Failed to create combiner:", err)
 			os.Exit(1)
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+
+	"github.com/stiganik/gap/initialization"
+	"github.com/stiganik/gap/solution"
+
+	_ "github.com/stiganik/gap/initialization/all"
+)
+
+const (
+	poolSize     = 4
+	solutionSize = 20
+)
+
+var algos = []initialization.Algorithm{
+	initialization.UNIFORM,
+	initialization.BERNOULLI,
+	initialization.STRATIFIED,
+	initialization.OPPOSITION,
+	initialization.HEURISTIC,
+}
+
+// alternate is a heuristic generator creating solutions of alternating bits.
+func alternate(buf []byte, rnd *rand.Rand) error {
+	for i := range buf {
+		buf[i] = 0x55
+	}
+	return nil
+}
+
+func main() {
+	params := initialization.Params{
+		Probability: 0.1,
+		Heuristic:   alternate,
+	}
+
+	for _, algo := range algos {
+		pool := solution.NewPool(poolSize, solutionSize)
+
+		initializer, err := initialization.New(algo, initialization.Options{Params: params})
+		if err != nil {
+			fmt.Println("Failed to create initializer:", err)
+			os.Exit(1)
+		}
+
+		if err = initializer.Initialize(pool); err != nil {
+			fmt.Println("Failed to initialize values:", err)
+			os.Exit(1)
+		}
+
+		fmt.Println("Algorithm:", string(algo))
+		for _, s := range pool.Specimens {
+			fmt.Printf("%08b\n", s.Buf)
+		}
+		fmt.Println()
+	}
+}
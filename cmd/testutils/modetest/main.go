@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/stiganik/gap"
+)
+
+const (
+	solutionSize = 64
+	generations  = 12
+)
+
+// Fitness counts the set bits of the solution.
+func Fitness(s []byte) uint {
+	var fitness uint
+	for _, b := range s {
+		for ; b != 0; b >>= 1 {
+			fitness += uint(b & 1)
+		}
+	}
+	return fitness
+}
+
+func newAlgorithm(seed int64) *gap.Algorithm {
+	return &gap.Algorithm{
+		FFn:              Fitness,
+		SolutionBitSize:  solutionSize,
+		SolutionPoolSize: 100,
+		Seed:             &seed,
+		RecordHistory:    true,
+		HallOfFameSize:   5,
+	}
+}
+
+// comparable clears the timing information of res, which differs between
+// otherwise identical runs.
+func comparable(res gap.Result) gap.Result {
+	res.ElapsedTime = 0
+	history := make(gap.History, len(res.History))
+	for i, info := range res.History {
+		info.ElapsedTime = 0
+		history[i] = info
+	}
+	res.History = history
+	return res
+}
+
+// testResume checks that a run stopped at a checkpoint and resumed from it
+// gives the same result as an uninterrupted run.
+func testResume(dir string) error {
+	goal := gap.Goal{Goals: gap.GENERATION, GenN: generations}
+
+	full, err := newAlgorithm(1).Run(goal)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, "run.checkpoint")
+	alg := newAlgorithm(1)
+	alg.CheckpointPath = path
+	alg.CheckpointInterval = 5
+	if _, err = alg.Run(gap.Goal{Goals: gap.GENERATION, GenN: 7}); err != nil {
+		return err
+	}
+
+	alg = newAlgorithm(1)
+	alg.CheckpointPath = path
+	resumed, err := alg.Resume(path, goal)
+	if err != nil {
+		return err
+	}
+
+	if !reflect.DeepEqual(comparable(full), comparable(resumed)) {
+		return fmt.Errorf("Resumed result differs: fitness %v after %d generations, expected %v after %d",
+			resumed.Solution.Fitness, resumed.Generation, full.Solution.Fitness, full.Generation)
+	}
+	fmt.Println("Resume: generation", resumed.Generation, "fitness", resumed.Solution.Fitness)
+	return nil
+}
+
+// testSteadyState checks that a run in the steady state mode completes.
+func testSteadyState() error {
+	alg := newAlgorithm(2)
+	alg.Mode = gap.STEADY_STATE
+	alg.Offspring = 10
+	res, err := alg.Run(gap.Goal{Goals: gap.GENERATION, GenN: generations})
+	if err != nil {
+		return err
+	}
+	if res.Generation != generations {
+		return fmt.Errorf("Steady state run stopped at generation %d", res.Generation)
+	}
+	fmt.Println("Steady state: generation", res.Generation, "fitness", res.Solution.Fitness)
+	return nil
+}
+
+// testIslands checks that a run of the island model completes.
+func testIslands() error {
+	seed := int64(3)
+	m := gap.IslandModel{
+		Islands:           []*gap.Algorithm{newAlgorithm(0), newAlgorithm(0), newAlgorithm(0)},
+		MigrationInterval: 4,
+		Topology:          gap.TOPOLOGY_FULLY_CONNECTED,
+		Seed:              &seed,
+	}
+	res, err := m.Run(gap.Goal{Goals: gap.GENERATION, GenN: generations})
+	if err != nil {
+		return err
+	}
+	if res.Generation != generations || len(res.Islands) != len(m.Islands) {
+		return fmt.Errorf("Island run stopped at generation %d with %d islands", res.Generation, len(res.Islands))
+	}
+	fmt.Println("Islands: generation", res.Generation, "fitness", res.Solution.Fitness)
+	return nil
+}
+
+func main() {
+	dir, err := os.MkdirTemp("", "modetest")
+	if err != nil {
+		fmt.Println("Failed to create a temporary directory:", err)
+		os.Exit(1)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name string
+		fn   func() error
+	}{
+		{"resume", func() error { return testResume(dir) }},
+		{"steady state", testSteadyState},
+		{"islands", testIslands},
+	}
+
+	failed := false
+	for _, test := range tests {
+		if err := test.fn(); err != nil {
+			fmt.Printf("Test %s failed: %v\n", test.name, err)
+			failed = true
+		}
+	}
+	if failed {
+		os.RemoveAll(dir)
+		os.Exit(1)
+	}
+}
@@ -16,7 +16,15 @@ const (
 	elitism      = 10
 )
 
-var tests = []selection.Algorithm{selection.SCX}
+var tests = []selection.Algorithm{
+	selection.SCX,
+	selection.TOURNAMENT,
+	selection.RANK_LINEAR,
+	selection.RANK_EXPONENTIAL,
+	selection.SUS,
+	selection.TRUNCATION,
+	selection.BOLTZMANN,
+}
 
 func main() {
 	poolA := solution.NewPool(poolSize, solutionSize)
@@ -29,11 +37,11 @@ func main() {
 	}
 
 	for i := range poolA.Specimens {
-		poolA.Specimens[i].Fitness = uint(i)
+		poolA.Specimens[i].Fitness = float64(i)
 	}
 
 	poolA.Specimens.SortDesc()
-	before := make([]uint, poolSize, poolSize)
+	before := make([]float64, poolSize, poolSize)
 
 	for i := range poolA.Specimens {
 		before[i] = poolA.Specimens[i].Fitness
@@ -43,7 +51,7 @@ func main() {
 	for _, test := range tests {
 		fmt.Println("\nAlgorithm:", string(test))
 
-		sel, err := selection.New(test, elitism)
+		sel, err := selection.New(test, selection.Options{Elitism: elitism})
 		if err != nil {
 			fmt.Println("Failed to create select algorithm:", err)
 			os.Exit(1)
@@ -55,7 +63,7 @@ func main() {
 
 		poolB.Specimens.SortDesc()
 
-		after := make([]uint, poolSize, poolSize)
+		after := make([]float64, poolSize, poolSize)
 		for i := range poolB.Specimens {
 			after[i] = poolB.Specimens[i].Fitness
 		}
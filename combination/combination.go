@@ -5,7 +5,9 @@ package combination
 
 import (
 	"fmt"
+	"math/rand"
 	"sync"
+	"time"
 
 	"github.com/stiganik/gap/solution"
 )
@@ -40,10 +42,28 @@ const (
 var syncMutex sync.RWMutex
 var algorithms map[Algorithm]NewFunc
 
+// Options contains the settings an algorithm implementation is created with.
+type Options struct {
+	// Elitism is the percetage of solutions that should be considered
+	// "elite" and left unaltered.
+	Elitism uint
+
+	// Source is the source of random numbers used by the algorithm. If it
+	// is nil, a source seeded with the current time is used.
+	Source rand.Source
+}
+
+// Rand returns a random number generator using the source of the options.
+func (o Options) Rand() *rand.Rand {
+	if o.Source == nil {
+		return rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return rand.New(o.Source)
+}
+
 // NewFunc creates a new instance of the algorithm implementation this function
-// belongs to. Elitism is the percetage of solutions that should be considered
-// "elite" and left unaltered.
-type NewFunc func(elitism uint) (Combiner, error)
+// belongs to.
+type NewFunc func(opts Options) (Combiner, error)
 
 // Register registers a new combination algorithm for use through the Combiner
 // interface.
@@ -58,7 +78,7 @@ func Register(alg Algorithm, new NewFunc) {
 }
 
 // New creates a new instance of the selection algorithm defined by alg.
-func New(alg Algorithm, elitism uint) (Combiner, error) {
+func New(alg Algorithm, opts Options) (Combiner, error) {
 	syncMutex.RLock()
 	defer syncMutex.RUnlock()
 
@@ -67,7 +87,7 @@ func New(alg Algorithm, elitism uint) (Combiner, error) {
 		return nil, fmt.Errorf("Algorithm not linked: %s", alg)
 	}
 
-	return newFn(elitism)
+	return newFn(opts)
 }
 
 // Combiner is the interface for all combination algorithms in this project.
@@ -79,6 +99,8 @@ type Combiner interface {
 	//
 	// The combination algorithm MAY change the existing byte values in the
 	// solution pool. The combination algorithm MUST NOT change the length
-	// and/or capacity of the solution pool or the solutions.
+	// and/or capacity of the solution pool or the solutions. The
+	// combination algorithm MUST NOT set the padding bits of the solutions,
+	// see solution.Pool for the bit order.
 	Combine(pool solution.Pool) error
 }
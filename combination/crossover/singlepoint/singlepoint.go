@@ -7,7 +7,6 @@ package singlepoint
 import (
 	"fmt"
 	"math/rand"
-	"time"
 
 	"github.com/stiganik/gap/combination"
 	"github.com/stiganik/gap/solution"
@@ -23,10 +22,10 @@ type singlePoint struct {
 }
 
 // New creates an instance of the single point crossover technique.
-func New(elitism uint) (combination.Combiner, error) {
+func New(opts combination.Options) (combination.Combiner, error) {
 	return &singlePoint{
-		elitism: elitism,
-		rnd:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		elitism: opts.Elitism,
+		rnd:     opts.Rand(),
 	}, nil
 }
 
@@ -64,7 +63,8 @@ func spliceByte(pivot int, a, b byte) (ra byte, rb byte) {
 }
 
 // Combine combines two solution by selecting a random pivoting point (in bits)
-// and splicing the solutions together into two output solutions.
+// and splicing the solutions together into two output solutions. The pivot is
+// chosen among the bits of the solution, the padding bits are never used.
 //
 // Solution A: ----------
 // Solution B: //////////
@@ -93,7 +93,7 @@ func (s *singlePoint) Combine(pool solution.Pool) error {
 		a := specimens[i].Buf
 		b := specimens[i+1].Buf
 
-		r := s.rnd.Intn(len(a) * 8)
+		r := s.rnd.Intn(int(pool.SpecimenBitSize))
 		pBytes, pBits := byteValue(r)
 
 		copy(tmp, a[(pBytes+1):len(a)])
@@ -7,7 +7,6 @@ package twopoint
 import (
 	"fmt"
 	"math/rand"
-	"time"
 
 	"github.com/stiganik/gap/combination"
 	"github.com/stiganik/gap/solution"
@@ -23,10 +22,10 @@ type twopoint struct {
 }
 
 // New creates an instance of the two point crossover technique.
-func New(elitism uint) (combination.Combiner, error) {
+func New(opts combination.Options) (combination.Combiner, error) {
 	return &twopoint{
-		elitism: elitism,
-		rnd:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		elitism: opts.Elitism,
+		rnd:     opts.Rand(),
 	}, nil
 }
 
@@ -63,7 +62,9 @@ func spliceByte(pivot int, a, b byte) (ra byte, rb byte) {
 }
 
 // Combine combines two solution by selecting two random pivoting points (in
-// bits) and splicing the solutions together into two output solutions.
+// bits) and splicing the solutions together into two output solutions. The
+// pivots are chosen among the bits of the solution, the padding bits are never
+// used.
 //
 // Solution A: --------------------
 // Solution B: ////////////////////
@@ -93,8 +94,8 @@ func (t *twopoint) Combine(pool solution.Pool) error {
 		a := specimens[i].Buf
 		b := specimens[i+1].Buf
 
-		r1 := t.rnd.Intn(len(a) * 8)
-		r2 := t.rnd.Intn(len(b) * 8)
+		r1 := t.rnd.Intn(int(pool.SpecimenBitSize))
+		r2 := t.rnd.Intn(int(pool.SpecimenBitSize))
 		if r1 > r2 {
 			tmp := r2
 			r2 = r1
@@ -7,14 +7,11 @@ package bitstring
 import (
 	"math"
 	"math/rand"
-	"time"
 
 	"github.com/stiganik/gap/combination"
 	"github.com/stiganik/gap/solution"
 )
 
-var mask = []byte{128, 64, 32, 16, 8, 4, 2, 1}
-
 func init() {
 	combination.Register(combination.MUTATION_BIT_STRING, New)
 }
@@ -25,10 +22,10 @@ type bitstring struct {
 }
 
 // New creates an instance of the bit string mutation technique.
-func New(elitism uint) (combination.Combiner, error) {
+func New(opts combination.Options) (combination.Combiner, error) {
 	return &bitstring{
-		elitism: elitism,
-		rnd:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		elitism: opts.Elitism,
+		rnd:     opts.Rand(),
 	}, nil
 }
 
@@ -76,7 +73,7 @@ func (b *bitstring) Combine(pool solution.Pool) error {
 
 		for j := uint(0); j < mutatedUint; j++ {
 			target := b.rnd.Intn(int(pool.SpecimenBitSize))
-			specimens[i].Buf[target/8] ^= mask[target%8]
+			specimens[i].Buf[target/8] ^= 1 << (target % 8)
 		}
 	}
 
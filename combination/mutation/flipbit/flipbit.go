@@ -18,13 +18,14 @@ type flipbit struct {
 }
 
 // New creates an instance of the flip bit mutation technique.
-func New(elitism uint) (combination.Combiner, error) {
+func New(opts combination.Options) (combination.Combiner, error) {
 	return &flipbit{
-		elitism: elitism,
+		elitism: opts.Elitism,
 	}, nil
 }
 
-// Combine mutates one solution at a time by flipping all bits to their opposite
+// Combine mutates one solution at a time by flipping all bits to their
+// opposite. The padding bits are left unset.
 //
 // Solution A: 00000000
 // OutA: 11111111
@@ -39,6 +40,7 @@ func (f *flipbit) Combine(pool solution.Pool) error {
 		for j := range specimens[i].Buf {
 			specimens[i].Buf[j] = ^specimens[i].Buf[j]
 		}
+		solution.ClearPadding(specimens[i].Buf, pool.SpecimenBitSize)
 	}
 
 	return nil
@@ -0,0 +1,331 @@
+package gap
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/stiganik/gap/solution"
+)
+
+// evaluate calculates the fitness of every specimen using at most
+// a.ThreadCount goroutines. If the fitness cache is enabled, specimens with a
+// cached fitness are not evaluated and duplicate specimens are evaluated only
+// once. With a batch fitness function the specimens are evaluated in batches
+// of consecutive specimens, which are always evaluated in full.
+//
+// The fitness function calls are handed out in pool order, so once a specimen
+// reaches the fitness goal only the specimens before it still need to be
+// evaluated. The index of the first specimen (in pool order) that reached the
+// fitness goal is returned, or -1 if none of them did. The specimens whose
+// fitness is known are returned next. If the evaluation budget of the goal
+// runs out, only a prefix of the pool is evaluated and returned. The
+// evaluation is cut short if the goal context is done, leaving the rest of the
+// specimens unevaluated, in which case copies of the evaluated specimens
+// sharing their genomes are returned. The amount of specimens evaluated by
+// the fitness function is returned next. Specimens
+// the fitness function fails to evaluate are handled according to the failure
+// policy of the algorithm, the error of the first one in pool order is returned
+// if the policy is FAIL_ABORT.
+func (r *run) evaluate(specimens solution.Specimens, g *Goal) (found int, finished solution.Specimens, calls uint64, err error) {
+	a := r.a
+	n := len(specimens)
+
+	// Assign a job to every specimen that needs to be evaluated. owner
+	// contains the index of the job of every specimen or -1 if the fitness
+	// of the specimen was cached. jobs contains the index of the first
+	// specimen of every job.
+	owner := r.owner[:n]
+	jobs := r.jobs[:0]
+	first := int64(n)
+
+	var pending map[string]int
+	if r.cache != nil {
+		pending = make(map[string]int)
+	}
+	for i := range specimens {
+		if r.cache == nil {
+			owner[i] = len(jobs)
+			jobs = append(jobs, i)
+			continue
+		}
+
+		if fitness, ok := r.cache.get(specimens[i].Buf); ok {
+			specimens[i].Fitness = fitness
+			owner[i] = -1
+			r.cache.hits++
+			if g.fitnessReached(fitness) && int64(i) < first {
+				first = int64(i)
+			}
+			continue
+		}
+
+		key := string(specimens[i].Buf)
+		if j, ok := pending[key]; ok {
+			owner[i] = j
+			r.cache.hits++
+			continue
+		}
+		pending[key] = len(jobs)
+		owner[i] = len(jobs)
+		jobs = append(jobs, i)
+	}
+	r.jobs = jobs
+
+	evaluated := n
+	if budget := g.evaluationBudget(r.totalEvaluations(), len(jobs)); budget < len(jobs) {
+		evaluated = jobs[budget]
+		jobs = jobs[:budget]
+	}
+
+	workers := int(a.ThreadCount)
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+
+	// Every job is evaluated separately unless a batch fitness function is
+	// used, in which case the jobs are split into batches of size jobs.
+	batch := a.batch()
+	size := 1
+	if batch && len(jobs) > 0 {
+		size = (len(jobs) + workers - 1) / workers
+		if a.BatchSize > 0 && int(a.BatchSize) < size {
+			size = int(a.BatchSize)
+		}
+	}
+
+	// done marks the jobs that were evaluated, errs holds the errors of the
+	// jobs that failed to be evaluated and timeouts marks the jobs whose
+	// evaluation timed out.
+	done := make([]bool, len(jobs))
+	errs := make([]error, len(jobs))
+	timeouts := make([]bool, len(jobs))
+	next := int64(-1)
+	ctxDone := g.ctx.Done()
+
+	// fail records the failure of job j. If the run is aborted, the other
+	// workers are stopped as well.
+	fail := func(j int, ferr error) {
+		errs[j] = ferr
+		done[j] = true
+		if a.OnFailure == FAIL_ABORT {
+			storeMin(&first, -1)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var genomes [][]byte
+			var out []float64
+			var tmp []uint
+			if batch {
+				genomes = make([][]byte, 0, size)
+				out = make([]float64, size)
+				tmp = make([]uint, size)
+			}
+
+			for {
+				lo := int(atomic.AddInt64(&next, 1)) * size
+				if lo >= len(jobs) || int64(jobs[lo]) > atomic.LoadInt64(&first) {
+					return
+				}
+				select {
+				case <-ctxDone:
+					return
+				default:
+				}
+
+				if !batch {
+					i := jobs[lo]
+					fitness, timedOut, ferr := r.evaluateOne(g, specimens[i].Buf)
+					if ferr != nil && g.ctx.Err() != nil {
+						// The algorithm was stopped during the
+						// evaluation.
+						return
+					}
+					atomic.AddUint64(&calls, 1)
+					if timedOut {
+						timeouts[lo] = true
+						done[lo] = true
+						continue
+					}
+					if ferr != nil {
+						fail(lo, ferr)
+						continue
+					}
+					specimens[i].Fitness = fitness
+					done[lo] = true
+					if g.fitnessReached(fitness) {
+						storeMin(&first, int64(i))
+						return
+					}
+					continue
+				}
+
+				hi := lo + size
+				if hi > len(jobs) {
+					hi = len(jobs)
+				}
+				genomes = genomes[:0]
+				for _, i := range jobs[lo:hi] {
+					genomes = append(genomes, specimens[i].Buf)
+				}
+				ferr := a.safeBatchFitness(genomes, out[:hi-lo], tmp[:hi-lo])
+				atomic.AddUint64(&calls, uint64(hi-lo))
+				for k, fitness := range out[:hi-lo] {
+					if ferr != nil {
+						fail(lo+k, ferr)
+						continue
+					}
+					i := jobs[lo+k]
+					specimens[i].Fitness = fitness
+					done[lo+k] = true
+					if g.fitnessReached(fitness) {
+						storeMin(&first, int64(i))
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Record the worst fitness before assigning it to the solutions that
+	// failed to be evaluated. Every solution is offered to the hall of fame
+	// once, when it is evaluated, so the cached solutions and the elite
+	// solutions of the previous generation are not offered again.
+	survivors := 0
+	if r.generation > 0 {
+		survivors = r.elite
+	}
+	for j, i := range jobs {
+		if done[j] && errs[j] == nil && !timeouts[j] {
+			r.observe(specimens[i].Fitness)
+			if i >= survivors {
+				r.hof.offer(specimens[i])
+			}
+		}
+	}
+	for j, i := range jobs {
+		if timeouts[j] {
+			specimens[i].Fitness = r.timeoutFitness()
+			r.timeouts++
+			continue
+		}
+		if errs[j] == nil {
+			continue
+		}
+		if a.OnFailure == FAIL_ABORT && err == nil {
+			err = &FitnessError{
+				Genome: append([]byte(nil), specimens[i].Buf...),
+				Err:    errs[j],
+			}
+		}
+		specimens[i].Fitness = r.worstFitness()
+		r.failures++
+	}
+	if err != nil {
+		return -1, nil, calls, err
+	}
+
+	// Copy the fitness to the duplicate specimens and find the first
+	// specimen that reached the fitness goal.
+	found = -1
+	for i := 0; i < evaluated; i++ {
+		j := owner[i]
+		if j >= 0 {
+			if !done[j] {
+				break
+			}
+			specimens[i].Fitness = specimens[jobs[j]].Fitness
+		}
+		if (j < 0 || errs[j] == nil && !timeouts[j]) && g.fitnessReached(specimens[i].Fitness) {
+			found = i
+			break
+		}
+	}
+
+	if r.cache != nil {
+		for j, i := range jobs {
+			if done[j] && errs[j] == nil && !timeouts[j] {
+				r.cache.add(specimens[i].Buf, specimens[i].Fitness)
+			}
+		}
+		r.cache.misses += calls
+	}
+
+	// If the evaluation was cut short, the evaluated specimens are spread
+	// over the pool.
+	finished = specimens[:evaluated]
+	if g.ctx.Err() != nil {
+		finished = nil
+		for i := 0; i < evaluated; i++ {
+			if j := owner[i]; j >= 0 {
+				if !done[j] {
+					continue
+				}
+				specimens[i].Fitness = specimens[jobs[j]].Fitness
+			}
+			finished = append(finished, specimens[i])
+		}
+	}
+	return
+}
+
+// evaluation is the outcome of a single fitness function call.
+type evaluation struct {
+	fitness float64
+	err     error
+}
+
+// evaluateOne evaluates the solution s with the fitness function of the
+// algorithm. The evaluation is limited by the evaluation timeout of the
+// algorithm and timedOut is set if it ran out. If the goal context is done
+// before the evaluation finishes, its error is returned.
+//
+// With a timeout the fitness function is called in a goroutine of its own on a
+// copy of s, so a call that does not return in time can be abandoned. The
+// abandoned goroutine keeps running until the fitness function returns.
+func (r *run) evaluateOne(g *Goal, s []byte) (fitness float64, timedOut bool, err error) {
+	a := r.a
+	if a.EvaluationTimeout <= 0 {
+		fitness, err = a.safeFitness(g.ctx, s)
+		return fitness, false, err
+	}
+
+	ctx, cancel := context.WithTimeout(g.ctx, a.EvaluationTimeout)
+	defer cancel()
+
+	buf := append([]byte(nil), s...)
+	result := make(chan evaluation, 1)
+	go func() {
+		fitness, err := a.safeFitness(ctx, buf)
+		result <- evaluation{fitness, err}
+	}()
+
+	select {
+	case e := <-result:
+		if e.err != nil && ctx.Err() == context.DeadlineExceeded && g.ctx.Err() == nil {
+			return 0, true, nil
+		}
+		return e.fitness, false, e.err
+	case <-ctx.Done():
+		if cerr := g.ctx.Err(); cerr != nil {
+			return 0, false, cerr
+		}
+		return 0, true, nil
+	}
+}
+
+// storeMin atomically stores v in addr if it is smaller than the current value.
+func storeMin(addr *int64, v int64) {
+	for {
+		cur := atomic.LoadInt64(addr)
+		if v >= cur || atomic.CompareAndSwapInt64(addr, cur, v) {
+			return
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package gap
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/stiganik/gap/solution"
+)
+
+// FailurePolicy determines what happens when the fitness function fails to
+// evaluate a solution, i.e. returns an error or panics.
+type FailurePolicy uint
+
+const (
+	// FAIL_ABORT stops the algorithm with a FitnessError.
+	FAIL_ABORT FailurePolicy = iota
+
+	// FAIL_WORST assigns the worst fitness found so far in the run to the
+	// solution and continues the algorithm. If no solution has been
+	// evaluated successfully yet, the fitness is positive infinity when
+	// minimizing and negative infinity when maximizing.
+	FAIL_WORST
+)
+
+// FitnessError is returned by the algorithm when the fitness function fails
+// to evaluate a solution and the failure policy is FAIL_ABORT. For a batch
+// fitness function the solution is the first solution of the failed batch.
+type FitnessError struct {
+	// Genome is a copy of the solution that could not be evaluated.
+	Genome []byte
+
+	// Err is the error returned by the fitness function or an error
+	// describing the panic.
+	Err error
+}
+
+func (e *FitnessError) Error() string {
+	return fmt.Sprintf("Fitness evaluation of solution %x failed: %v", e.Genome, e.Err)
+}
+
+func (e *FitnessError) Unwrap() error {
+	return e.Err
+}
+
+// safeFitness evaluates the solution s with the fitness function of the
+// algorithm, turning a panic of the fitness function into an error.
+func (a *Algorithm) safeFitness(ctx context.Context, s []byte) (fitness float64, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("Fitness function panicked: %v", p)
+		}
+	}()
+	return a.fitness(ctx, s)
+}
+
+// safeBatchFitness is like safeFitness for the batch fitness function.
+func (a *Algorithm) safeBatchFitness(genomes [][]byte, out []float64, tmp []uint) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("Batch fitness function panicked: %v", p)
+		}
+	}()
+	return a.batchFitness(genomes, out, tmp)
+}
+
+// worstFitness returns the fitness assigned to the solutions that could not be
+// evaluated.
+func (r *run) worstFitness() float64 {
+	if !r.worstKnown {
+		if r.a.Direction == solution.MINIMIZE {
+			return math.Inf(1)
+		}
+		return math.Inf(-1)
+	}
+	return r.worst
+}
+
+// timeoutFitness returns the fitness assigned to the solutions whose
+// evaluation timed out.
+func (r *run) timeoutFitness() float64 {
+	if r.a.TimeoutFitness != nil {
+		return *r.a.TimeoutFitness
+	}
+	return r.worstFitness()
+}
+
+// observe records fitness as the worst fitness found so far if it is worse than
+// all of the previous ones.
+func (r *run) observe(fitness float64) {
+	if !r.worstKnown || r.a.Direction.Better(r.worst, fitness) {
+		r.worstKnown = true
+		r.worst = fitness
+	}
+}
@@ -5,41 +5,132 @@ using advanced crossover and mutation techniques.
 package gap
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"runtime"
 	"time"
 
 	"github.com/stiganik/gap/combination"
+	"github.com/stiganik/gap/initialization"
 	"github.com/stiganik/gap/selection"
 	"github.com/stiganik/gap/solution"
 
-	// Statically import all selection and combination algorithms to make
-	// them register themselves at runtime.
+	// Statically import all initialization, selection and combination
+	// algorithms to make them register themselves at runtime.
 	_ "github.com/stiganik/gap/combination/all"
+	_ "github.com/stiganik/gap/initialization/all"
 	_ "github.com/stiganik/gap/selection/all"
 )
 
 var (
-	defaultPoolSize       = uint(1000)
-	defaultElitism        = uint(3)
-	defaultSelectionAlg   = selection.SCX
-	defaultCombinationAlg = []combination.Algorithm{
+	defaultPoolSize          = uint(1000)
+	defaultElitism           = uint(3)
+	defaultInitializationAlg = initialization.UNIFORM
+	defaultSelectionAlg      = selection.SCX
+	defaultCombinationAlg    = []combination.Algorithm{
 		combination.CROSSOVER_SINGLE_POINT,
 	}
-	defaultThreadCount = uint(runtime.NumCPU())
+	defaultThreadCount        = uint(runtime.NumCPU())
+	defaultCheckpointInterval = uint(10)
+	defaultOffspring          = uint(2)
 )
 
+// ErrCanceled is returned by RunContext when its context is done before the
+// goal of the algorithm is reached. The error returned also wraps the error of
+// the context.
+var ErrCanceled = errors.New("algorithm canceled")
+
 // FitnessFn defines a fitness function which takes in a solution in the form of
 // a byte array calculates the fitness of the the solution and expresses it as a
 // unsigned integer value from less to more fit, zero being totally unsuitable.
+// The function is called from multiple goroutines at once and must be safe for
+// concurrent use.
 type FitnessFn func(s []byte) uint
 
+// FloatFitnessFn is like FitnessFn, but expresses the fitness as a float64
+// value. Whether higher or lower values are more fit is determined by the
+// direction of the algorithm.
+type FloatFitnessFn func(s []byte) float64
+
+// FallibleFitnessFn is like FloatFitnessFn, but can report that the solution
+// could not be evaluated by returning an error.
+type FallibleFitnessFn func(s []byte) (float64, error)
+
+// ContextFitnessFn is like FallibleFitnessFn, but receives a context that is
+// done once the evaluation times out or the algorithm is stopped. The function
+// should return as soon as possible once the context is done.
+type ContextFitnessFn func(ctx context.Context, s []byte) (float64, error)
+
+// BatchFitnessFn defines a fitness function which calculates the fitness of
+// many solutions at once, e.g. using a vectorized model. The fitness of every
+// solution in genomes is stored at the same index of out. The function may be
+// called with distinct batches from multiple goroutines at once and must be safe
+// for concurrent use. The genomes must not be modified or retained after the
+// call.
+type BatchFitnessFn func(genomes [][]byte, out []uint) error
+
+// FloatBatchFitnessFn is like BatchFitnessFn, but expresses the fitness as
+// float64 values.
+type FloatBatchFitnessFn func(genomes [][]byte, out []float64) error
+
 // Algorithm defines a problem and the genetic algorithm used to solve the
 // problem.
 type Algorithm struct {
-	// The fitness function used to evaluate solutions.
+	// The fitness function used to evaluate solutions. Exactly one of FFn,
+	// FloatFFn, FallibleFFn, ContextFFn, BatchFFn and FloatBatchFFn must be
+	// set.
 	FFn FitnessFn
 
+	// FloatFFn is the fitness function used to evaluate solutions with
+	// floating point fitness values.
+	FloatFFn FloatFitnessFn
+
+	// FallibleFFn is the fitness function used to evaluate solutions that
+	// may fail to be evaluated.
+	FallibleFFn FallibleFitnessFn
+
+	// ContextFFn is the fitness function used to evaluate solutions with a
+	// context.
+	ContextFFn ContextFitnessFn
+
+	// EvaluationTimeout is the time a single call of the fitness function
+	// may take. The context passed to ContextFFn is done once the time runs
+	// out and the solution is assigned TimeoutFitness instead of the result
+	// of the call. It can not be used with the batch fitness functions. The
+	// algorithm does not wait for a call that has timed out, it is
+	// abandoned and keeps running in the background until the function
+	// returns. If the value is zero, the evaluations never time out.
+	EvaluationTimeout time.Duration
+
+	// TimeoutFitness is the penalty fitness assigned to the solutions whose
+	// evaluation timed out. If the value is nil, the worst fitness is
+	// assigned as with the FAIL_WORST failure policy.
+	TimeoutFitness *float64
+
+	// BatchFFn is the fitness function used to evaluate many solutions at
+	// once. If the function returns an error, all of the solutions of the
+	// batch failed to be evaluated.
+	BatchFFn BatchFitnessFn
+
+	// FloatBatchFFn is like BatchFFn, but with floating point fitness
+	// values.
+	FloatBatchFFn FloatBatchFitnessFn
+
+	// BatchSize is the maximum amount of solutions evaluated in one call of
+	// the batch fitness function. The batches are evaluated by ThreadCount
+	// goroutines in parallel. If the value is zero the solutions of a
+	// generation are split evenly between the goroutines.
+	BatchSize uint
+
+	// OnFailure determines what happens when the fitness function returns
+	// an error or panics. The default value is FAIL_ABORT.
+	OnFailure FailurePolicy
+
+	// Direction determines whether the algorithm maximizes or minimizes the
+	// fitness of the solutions. The default value is solution.MAXIMIZE.
+	Direction solution.Direction
+
 	// SolutionBitSize is the bit size of the solution slice.
 	SolutionBitSize uint
 
@@ -47,6 +138,29 @@ type Algorithm struct {
 	// iteration of the alogirthm. The default value is 1000.
 	SolutionPoolSize uint
 
+	// InitializationAlgorithm is the algorithm used to fill the solution
+	// pool with the initial solutions. The default value is
+	// initialization.UNIFORM.
+	InitializationAlgorithm initialization.Algorithm
+
+	// InitializationParams contains the parameters of the initialization
+	// algorithm, e.g. the bit probability of initialization.BERNOULLI.
+	InitializationParams initialization.Params
+
+	// InitialPopulation contains genomes the solution pool is seeded with
+	// after it has been filled by the initialization algorithm, e.g. known
+	// good heuristic solutions or the results of previous runs. Every
+	// genome must be exactly as long as a solution, i.e. SolutionBitSize
+	// rounded up to whole bytes, and have its padding bits unset. There can
+	// not be more genomes than solutions in the pool.
+	InitialPopulation [][]byte
+
+	// InitialFraction limits the part of the solution pool seeded from
+	// InitialPopulation to the given fraction of the pool size, the first
+	// genomes are used. It must be between 0 and 1. If the value is zero,
+	// all of the genomes are used.
+	InitialFraction float64
+
 	// Elitism is the percentage of values that pass on to the next generation
 	// without the selection and combination process. The value will be clipped
 	// between 0 and 100. If the value is nil the default value is used. The
@@ -57,22 +171,89 @@ type Algorithm struct {
 	// solution pool for crossover. The default value is selection.SCX.
 	SelectionAlgorithm selection.Algorithm
 
+	// SelectionParams contains the parameters of the selection algorithm,
+	// e.g. the tournament size of selection.TOURNAMENT.
+	SelectionParams selection.Params
+
 	// CombinationAlgorithms is a slice of algortihms used to combine the
 	// selected solutions into the solution candidates. The combination
 	// algorithms are applied sequentially. the default value is
 	// []combination.Algorithm{combination.CROSSOVER_SINGLE_POINT}
 	CombinationAlgorithms []combination.Algorithm
 
-	// ThreadCount sets the amount of threads used by the genetic algorithm.
-	// By default it is set to the number of physical cores the processor
-	// has.
-	// Note: Currently not in use.
+	// Mode determines whether the solution pool is replaced a generation
+	// at a time or a few solutions at a time. The default value is
+	// GENERATIONAL.
+	Mode Mode
+
+	// Offspring is the amount of offspring created and evaluated at a time
+	// in the STEADY_STATE mode. It must not be larger than the solution
+	// pool size and has to be divisible by 2 for the crossover algorithms.
+	// The default value is 2.
+	Offspring uint
+
+	// Replacement determines which solutions the offspring replace in the
+	// STEADY_STATE mode. The default value is REPLACE_WORST.
+	Replacement Replacement
+
+	// Seed is the seed of all random number generators used by the
+	// algorithm. Running the same algorithm with the same seed and goal
+	// gives identical results, apart from the elapsed time, as long as the
+	// fitness function is deterministic. If the value is nil, the current
+	// time is used as the seed.
+	Seed *int64
+
+	// Observers are notified about the progress of the algorithm after
+	// every generation.
+	Observers []Observer
+
+	// RecordHistory enables recording the statistics of every generation
+	// into the History field of the result.
+	RecordHistory bool
+
+	// CheckpointPath is the file the state of the algorithm is periodically
+	// saved to. A run can be continued from the file with Resume. If the
+	// value is empty no checkpoints are taken.
+	CheckpointPath string
+
+	// CheckpointInterval is the amount of generations between checkpoints.
+	// The default value is 10.
+	CheckpointInterval uint
+
+	// CacheSize is the maximum amount of fitness values cached. Solutions
+	// found in the cache are not evaluated again, e.g. the elite solutions
+	// and copies created by the selection algorithm. The least recently
+	// used values are evicted once the cache is full. The fitness function
+	// must be deterministic for the cache to be used. The cached values
+	// are not saved in checkpoints, so a resumed run starts with an empty
+	// cache and may evaluate more solutions than an uninterrupted one. If
+	// the value is zero, caching is disabled.
+	CacheSize uint
+
+	// HallOfFameSize is the amount of the best solutions ever evaluated
+	// kept in the hall of fame returned in the HallOfFame field of the
+	// result. If the value is zero, no hall of fame is kept.
+	HallOfFameSize uint
+
+	// HallOfFameUnique keeps only one copy of every genome in the hall of
+	// fame. Otherwise identical genomes bred independently may occupy
+	// several places.
+	HallOfFameUnique bool
+
+	// ThreadCount sets the amount of goroutines used to evaluate the fitness
+	// of the solutions. By default it is set to the number of logical CPUs
+	// usable by the process.
 	ThreadCount uint
 }
 
 func (a *Algorithm) check() error {
-	if a.FFn == nil {
+	if n := a.fitnessFunctions(); n == 0 {
 		return fmt.Errorf("Fitness function missing")
+	} else if n > 1 {
+		return fmt.Errorf("Multiple fitness functions set")
+	}
+	if a.Direction != solution.MAXIMIZE && a.Direction != solution.MINIMIZE {
+		return fmt.Errorf("Unknown direction: %d", a.Direction)
 	}
 	if a.SolutionBitSize == 0 {
 		return fmt.Errorf("Solution size 0")
@@ -80,6 +261,23 @@ func (a *Algorithm) check() error {
 	if a.SolutionPoolSize == 0 {
 		a.SolutionPoolSize = defaultPoolSize
 	}
+	if a.InitializationAlgorithm == "" {
+		a.InitializationAlgorithm = defaultInitializationAlg
+	}
+	if uint(len(a.InitialPopulation)) > a.SolutionPoolSize {
+		return fmt.Errorf("Initial population larger than pool size")
+	}
+	for i, genome := range a.InitialPopulation {
+		if uint(len(genome)) != (a.SolutionBitSize+7)/8 {
+			return fmt.Errorf("Initial genome %d size mismatch: %d", i, len(genome))
+		}
+		if solution.HasPadding(genome, a.SolutionBitSize) {
+			return fmt.Errorf("Initial genome %d has padding bits set", i)
+		}
+	}
+	if a.InitialFraction < 0 || a.InitialFraction > 1 {
+		return fmt.Errorf("Initial fraction out of range: %g", a.InitialFraction)
+	}
 	if a.Elitism == nil {
 		a.Elitism = &defaultElitism
 	} else {
@@ -96,15 +294,143 @@ func (a *Algorithm) check() error {
 	if a.ThreadCount == 0 {
 		a.ThreadCount = defaultThreadCount
 	}
+	if a.CheckpointInterval == 0 {
+		a.CheckpointInterval = defaultCheckpointInterval
+	}
+	if a.EvaluationTimeout > 0 && a.batch() {
+		return fmt.Errorf("Evaluation timeout not supported with batch fitness functions")
+	}
+	if a.OnFailure != FAIL_ABORT && a.OnFailure != FAIL_WORST {
+		return fmt.Errorf("Unknown failure policy: %d", a.OnFailure)
+	}
+	if a.Mode != GENERATIONAL && a.Mode != STEADY_STATE {
+		return fmt.Errorf("Unknown mode: %d", a.Mode)
+	}
+	if a.Replacement > REPLACE_PARENT {
+		return fmt.Errorf("Unknown replacement: %d", a.Replacement)
+	}
+	if a.Offspring == 0 {
+		a.Offspring = defaultOffspring
+	}
+	if a.Mode == STEADY_STATE && a.Offspring > a.SolutionPoolSize {
+		return fmt.Errorf("Offspring count larger than pool size")
+	}
+	if a.Mode == STEADY_STATE && a.Offspring%2 != 0 {
+		for _, comb := range a.CombinationAlgorithms {
+			if comb == combination.CROSSOVER_SINGLE_POINT || comb == combination.CROSSOVER_TWO_POINT {
+				return fmt.Errorf("Offspring count must be divisible by 2 for %s", comb)
+			}
+		}
+	}
 	return nil
 }
 
 // Result contains the result of a genetic algorithm and also additional
 // information about the running of the algorithm.
 type Result struct {
+	// StopReason is the reason reported by the goal or terminator that
+	// stopped the algorithm.
+	StopReason string
+
 	ElapsedTime time.Duration
 	Generation  uint
-	Solution    solution.Specimen
+
+	// Solution is the best solution found during the run. If the run was
+	// stopped before any solution was evaluated, its fitness is NaN and its
+	// genome is nil.
+	Solution solution.Specimen
+
+	// Evaluations is the amount of times the fitness function was called.
+	// Every solution evaluated by a batch fitness function counts as one
+	// evaluation.
+	Evaluations uint64
+
+	// CacheHits and CacheMisses are the amount of fitness values found
+	// and not found in the fitness cache if Algorithm.CacheSize is set.
+	// Duplicate solutions within a generation count as hits.
+	CacheHits   uint64
+	CacheMisses uint64
+
+	// Failures is the amount of solutions the fitness function failed to
+	// evaluate.
+	Failures uint64
+
+	// Timeouts is the amount of evaluations that timed out.
+	Timeouts uint64
+
+	// Seed is the seed the run was started with. Setting it as the seed of
+	// the algorithm reproduces the run.
+	Seed int64
+
+	// History contains the statistics of every generation if
+	// Algorithm.RecordHistory is set.
+	History History
+
+	// HallOfFame contains the best solutions ever evaluated sorted from
+	// best to worst if Algorithm.HallOfFameSize is set.
+	HallOfFame solution.Specimens
+
+	// Islands contains the results of the individual islands when the
+	// result is returned by an IslandModel.
+	Islands []Result
+}
+
+// initialPopulation returns the genomes the solution pool is seeded with.
+func (a *Algorithm) initialPopulation() [][]byte {
+	genomes := a.InitialPopulation
+	if a.InitialFraction > 0 {
+		if n := int(a.InitialFraction * float64(a.SolutionPoolSize)); n < len(genomes) {
+			genomes = genomes[:n]
+		}
+	}
+	return genomes
+}
+
+// fitnessFunctions returns the amount of fitness functions set.
+func (a *Algorithm) fitnessFunctions() int {
+	n := 0
+	for _, set := range []bool{
+		a.FFn != nil, a.FloatFFn != nil, a.FallibleFFn != nil,
+		a.ContextFFn != nil, a.BatchFFn != nil, a.FloatBatchFFn != nil,
+	} {
+		if set {
+			n++
+		}
+	}
+	return n
+}
+
+// batch reports whether the algorithm uses a batch fitness function.
+func (a *Algorithm) batch() bool {
+	return a.BatchFFn != nil || a.FloatBatchFFn != nil
+}
+
+// batchFitness evaluates the solutions in genomes into out with the batch
+// fitness function of the algorithm. tmp is a buffer as long as out.
+func (a *Algorithm) batchFitness(genomes [][]byte, out []float64, tmp []uint) error {
+	if a.FloatBatchFFn != nil {
+		return a.FloatBatchFFn(genomes, out)
+	}
+	if err := a.BatchFFn(genomes, tmp); err != nil {
+		return err
+	}
+	for i, f := range tmp {
+		out[i] = float64(f)
+	}
+	return nil
+}
+
+// fitness evaluates the solution s with the fitness function of the algorithm.
+func (a *Algorithm) fitness(ctx context.Context, s []byte) (float64, error) {
+	switch {
+	case a.ContextFFn != nil:
+		return a.ContextFFn(ctx, s)
+	case a.FallibleFFn != nil:
+		return a.FallibleFFn(s)
+	case a.FloatFFn != nil:
+		return a.FloatFFn(s), nil
+	}
+	return float64(a.FFn(s)), nil
 }
 
 // New creates a new default genetic algorithm for solving the problem described
@@ -120,101 +446,34 @@ func New(fn FitnessFn, sbl uint) *Algorithm {
 
 // Run runs the genetic algorithm and retrieves the correctest answer once the
 // goal of the algorithm is reached.
-func (a *Algorithm) Run(g Goal) (ret Result, err error) {
-	if err = a.check(); err != nil {
-		return
-	}
+func (a *Algorithm) Run(g Goal) (Result, error) {
+	return a.RunContext(context.Background(), g)
+}
 
-	if err = g.init(); err != nil {
-		return
+// RunContext is like Run, but stops the algorithm once ctx is done. The context
+// is also checked while the solutions are being evaluated, so the algorithm
+// does not have to finish the current generation before stopping. If ctx is
+// done before the goal is reached, the best solution found so far is returned
+// along with an error wrapping ErrCanceled.
+func (a *Algorithm) RunContext(ctx context.Context, g Goal) (Result, error) {
+	if err := a.check(); err != nil {
+		return Result{}, err
 	}
-	defer g.finalize()
 
-	poolA := solution.NewPool(a.SolutionPoolSize, a.SolutionBitSize)
-	poolB := solution.NewPool(a.SolutionPoolSize, a.SolutionBitSize)
-
-	if err = poolA.Seed(); err != nil {
-		return
+	seed := time.Now().UnixNano()
+	if a.Seed != nil {
+		seed = *a.Seed
 	}
 
-	sel, err := selection.New(a.SelectionAlgorithm, *a.Elitism)
+	r, err := a.newRun(seed)
 	if err != nil {
-		return
+		return Result{}, err
 	}
 
-	var combiners []combination.Combiner
-	for _, comb := range a.CombinationAlgorithms {
-		var c combination.Combiner
-		if c, err = combination.New(comb, *a.Elitism); err != nil {
-			return
-		}
-		combiners = append(combiners, c)
+	if err = g.init(ctx, a.Direction, 0); err != nil {
+		return Result{}, err
 	}
+	defer g.finalize()
 
-	curPool := &poolA
-	otherPool := &poolB
-
-	var best solution.Specimen
-	best.Copy((*curPool).Specimens[0])
-
-	generation := uint(0)
-	start := time.Now()
-	for {
-		if g.checkGen(generation) || g.checkTime() {
-			break
-		}
-		// XXX: Generate initial workers
-		// XXX: Distribute work to workers
-		for i := range curPool.Specimens {
-			fitness := a.FFn((*curPool).Specimens[i].Buf)
-			(*curPool).Specimens[i].Fitness = fitness
-			if g.checkFitness(fitness) {
-				ret.ElapsedTime = time.Since(start)
-				ret.Generation = generation
-				ret.Solution.Copy((*curPool).Specimens[i])
-				return
-			}
-		}
-
-		(*curPool).Specimens.SortDesc()
-		best.Copy((*curPool).Specimens[0])
-
-		if g.checkTime() {
-			break
-		}
-
-		if err = sel.Select((*curPool), (*otherPool)); err != nil {
-			return
-		}
-
-		if g.checkTime() {
-			break
-		}
-
-		for _, combiner := range combiners {
-			if err = combiner.Combine((*otherPool)); err != nil {
-				return
-			}
-		}
-
-		if g.checkTime() {
-			break
-		}
-
-		// Switch buffers and repeat genetic algorithm
-		if curPool == &poolA {
-			curPool = &poolB
-			otherPool = &poolA
-		} else {
-			curPool = &poolA
-			otherPool = &poolB
-		}
-		generation++
-	}
-
-	ret.ElapsedTime = time.Since(start)
-	ret.Generation = generation
-	ret.Solution.Copy(best)
-
-	return
+	return r.loop(&g)
 }
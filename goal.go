@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/stiganik/gap/solution"
 )
 
 // GoalFlag is a uint value type, that is used to specify different genetic
@@ -26,6 +28,20 @@ const (
 	// solutions and stops the algorithm after the fitness specified in the
 	// goal structure field FitN has been achieved.
 	FITNESS
+
+	// STAGNATION is a goal type flag. It monitors the progress of the
+	// algorithm and stops it after the best fitness has not improved for
+	// the amount of generations specified in the goal structure field
+	// StagN. If the field StagWindow is set, the algorithm is also stopped
+	// once the mean fitness has changed less than StagEpsilon over the
+	// last StagWindow generations.
+	STAGNATION
+
+	// EVALUATIONS is a goal type flag. It counts the calls to the fitness
+	// function and stops the algorithm once the amount specified in the goal
+	// structure field EvalN has been made. The budget is never exceeded,
+	// the algorithm stops in the middle of a generation if needed.
+	EVALUATIONS
 )
 
 // Goal is a structure that contains information about the goals of the
@@ -44,48 +60,169 @@ type Goal struct {
 	GenN uint
 
 	// If FITNESS is set - the fitness after which the algorithm will be
-	// cancelled.
-	FitN uint
+	// cancelled. When minimizing the algorithm is cancelled once the
+	// fitness is at most FitN.
+	FitN float64
+
+	// If STAGNATION is set - the amount of generations without improvement
+	// of the best fitness after which the algorithm will be cancelled. Zero
+	// disables the check.
+	StagN uint
+
+	// If STAGNATION is set - the amount of generations over which the
+	// change of the mean fitness is measured. Zero disables the check.
+	StagWindow uint
 
-	ctx    context.Context
-	cancel context.CancelFunc
-	term   bool
+	// If STAGNATION is set - the change of the mean fitness over StagWindow
+	// generations below which the algorithm will be cancelled. It must be
+	// positive if StagWindow is set.
+	StagEpsilon float64
+
+	// If EVALUATIONS is set - the amount of fitness function calls after
+	// which the algorithm will be cancelled.
+	EvalN uint64
+
+	// Terminator is a custom end condition. It is combined with the goal
+	// flags using OR, i.e. the algorithm stops once either one of the goal
+	// flags or the terminator is satisfied. Use And and Or to build more
+	// complex conditions.
+	Terminator Terminator
+
+	dir        solution.Direction
+	parent     context.Context
+	ctx        context.Context
+	cancel     context.CancelFunc
+	term       bool
+	reason     string
+	terminator Terminator
+	stagnation *stagnation
 }
 
-func (g *Goal) init() error {
-	g.ctx = nil
+// init prepares the goal for a run optimizing in the direction dir that has
+// already been going on for the elapsed duration.
+func (g *Goal) init(ctx context.Context, dir solution.Direction, elapsed time.Duration) error {
+	g.dir = dir
+	g.parent = ctx
+	g.ctx = ctx
 	g.cancel = nil
 	g.term = false
-	if g.Goals&(TIME|FITNESS|GENERATION) == 0 {
+	g.reason = ""
+	g.stagnation = nil
+	if g.Goals&(TIME|FITNESS|GENERATION|STAGNATION|EVALUATIONS) == 0 && g.Terminator == nil {
 		return fmt.Errorf("no goal set for algorithm")
 	}
+	if g.Goals&STAGNATION != 0 && g.StagN == 0 && g.StagWindow == 0 {
+		return fmt.Errorf("no stagnation condition set for algorithm")
+	}
+	if g.Goals&STAGNATION != 0 && g.StagWindow > 0 && g.StagEpsilon <= 0 {
+		return fmt.Errorf("stagnation epsilon must be positive")
+	}
+
+	// The built-in goals are expressed as terminators. TIME, GENERATION,
+	// FITNESS and EVALUATIONS are additionally checked during the
+	// generation so the algorithm can stop as soon as possible.
+	var ts []Terminator
 	if g.Goals&TIME != 0 {
-		g.ctx, g.cancel = context.WithTimeout(context.Background(), g.TimeN)
+		g.ctx, g.cancel = context.WithTimeout(ctx, g.TimeN-elapsed)
+		ts = append(ts, TimeLimit(g.TimeN))
+	}
+	if g.Goals&GENERATION != 0 {
+		ts = append(ts, GenerationLimit(g.GenN))
+	}
+	if g.Goals&FITNESS != 0 {
+		ts = append(ts, FitnessLimit(g.FitN))
+	}
+	if g.Goals&STAGNATION != 0 {
+		g.stagnation = &stagnation{n: g.StagN, window: g.StagWindow, epsilon: g.StagEpsilon}
+		ts = append(ts, g.stagnation)
+	}
+	if g.Goals&EVALUATIONS != 0 {
+		ts = append(ts, EvaluationLimit(g.EvalN))
 	}
+	if g.Terminator != nil {
+		ts = append(ts, g.Terminator)
+	}
+	g.terminator = Or(ts...)
 	return nil
 }
 
+// stop marks the goal as reached, keeping the reason of the first check that
+// reached it.
+func (g *Goal) stop(reason string) {
+	if !g.term {
+		g.term = true
+		g.reason = reason
+	}
+}
+
+// checkTime checks whether the time goal has been reached or the parent
+// context of the algorithm has been cancelled.
 func (g *Goal) checkTime() bool {
-	if g.Goals&TIME != 0 {
-		select {
-		case <-g.ctx.Done():
-			g.term = true
-		default:
+	select {
+	case <-g.ctx.Done():
+		if g.canceled() != nil {
+			g.stop(ReasonCanceled)
+		} else {
+			g.stop(ReasonTime)
 		}
+	default:
 	}
 	return g.term
 }
 
+// canceled returns the error of the parent context if it was cancelled
+// before the goal of the algorithm was reached.
+func (g *Goal) canceled() error {
+	return g.parent.Err()
+}
+
 func (g *Goal) checkGen(gen uint) bool {
 	if g.Goals&GENERATION != 0 && gen >= g.GenN {
-		g.term = true
+		g.stop(ReasonGeneration)
 	}
 	return g.term
 }
 
-func (g *Goal) checkFitness(fitness uint) bool {
-	if g.Goals&FITNESS != 0 && fitness >= g.FitN {
-		g.term = true
+func (g *Goal) checkFitness(fitness float64) bool {
+	if g.fitnessReached(fitness) {
+		g.stop(ReasonFitness)
+	}
+	return g.term
+}
+
+// fitnessReached reports whether fitness satisfies the fitness goal. Unlike
+// the other checks it does not modify the goal and is safe for concurrent use.
+func (g *Goal) fitnessReached(fitness float64) bool {
+	return g.Goals&FITNESS != 0 && !g.dir.Better(g.FitN, fitness)
+}
+
+func (g *Goal) checkEvaluations(evaluations uint64) bool {
+	if g.Goals&EVALUATIONS != 0 && evaluations >= g.EvalN {
+		g.stop(ReasonEvaluations)
+	}
+	return g.term
+}
+
+// evaluationBudget returns how many of the n specimens of the next generation
+// can be evaluated without exceeding the evaluation goal.
+func (g *Goal) evaluationBudget(evaluations uint64, n int) int {
+	if g.Goals&EVALUATIONS == 0 {
+		return n
+	}
+	if evaluations >= g.EvalN {
+		return 0
+	}
+	if left := g.EvalN - evaluations; left < uint64(n) {
+		return int(left)
+	}
+	return n
+}
+
+// checkTerminator consults the terminators of the goal with the statistics of
+// the latest generation.
+func (g *Goal) checkTerminator(info GenerationInfo) bool {
+	if stop, reason := g.terminator.Terminate(info); stop {
+		g.stop(reason)
 	}
 	return g.term
 }
@@ -0,0 +1,86 @@
+package gap
+
+import (
+	"bytes"
+
+	"github.com/stiganik/gap/solution"
+)
+
+// hallOfFame keeps the best solutions found during a run, sorted from best to
+// worst. A nil hall of fame ignores all solutions.
+type hallOfFame struct {
+	size      int
+	unique    bool
+	dir       solution.Direction
+	specimens solution.Specimens
+}
+
+func newHallOfFame(size uint, unique bool, dir solution.Direction) *hallOfFame {
+	return &hallOfFame{
+		size:      int(size),
+		unique:    unique,
+		dir:       dir,
+		specimens: make(solution.Specimens, 0, size),
+	}
+}
+
+// offer adds a copy of s to the hall of fame if it is good enough. It returns
+// false if s is not better than the worst solution of a full hall of fame, in
+// which case no worse solution will be admitted either.
+func (h *hallOfFame) offer(s solution.Specimen) bool {
+	if h == nil {
+		return false
+	}
+
+	n := len(h.specimens)
+	if n == h.size && (n == 0 || !h.dir.Better(s.Fitness, h.specimens[n-1].Fitness)) {
+		return false
+	}
+
+	if h.unique {
+		for i := range h.specimens {
+			if bytes.Equal(h.specimens[i].Buf, s.Buf) {
+				return true
+			}
+		}
+	}
+
+	// Reuse the buffer of the evicted solution if the hall of fame is full.
+	var entry solution.Specimen
+	if n == h.size {
+		entry = h.specimens[n-1]
+		h.specimens = h.specimens[:n-1]
+	}
+	entry.Copy(s)
+
+	i := len(h.specimens)
+	for i > 0 && h.dir.Better(entry.Fitness, h.specimens[i-1].Fitness) {
+		i--
+	}
+	h.specimens = append(h.specimens, solution.Specimen{})
+	copy(h.specimens[i+1:], h.specimens[i:])
+	h.specimens[i] = entry
+	return true
+}
+
+// offerSorted offers the solutions sorted from best to worst to the hall of
+// fame until one of them is rejected.
+func (h *hallOfFame) offerSorted(specimens solution.Specimens) {
+	for _, s := range specimens {
+		if !h.offer(s) {
+			return
+		}
+	}
+}
+
+// copies returns a copy of the solutions in the hall of fame.
+func (h *hallOfFame) copies() solution.Specimens {
+	if h == nil {
+		return nil
+	}
+	ret := make(solution.Specimens, len(h.specimens))
+	for i := range h.specimens {
+		ret[i].Copy(h.specimens[i])
+	}
+	return ret
+}
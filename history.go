@@ -0,0 +1,45 @@
+package gap
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// History is the per generation convergence history of the algorithm in the
+// order the generations were run.
+type History []GenerationInfo
+
+var historyHeader = []string{
+	"generation", "elapsed_seconds", "best", "mean", "median", "worst",
+	"stddev", "evaluations", "timeouts",
+}
+
+// WriteCSV writes the history to w as comma separated values with a header
+// row. The elapsed time is written in seconds.
+func (h History) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(historyHeader); err != nil {
+		return err
+	}
+
+	for _, info := range h {
+		record := []string{
+			strconv.FormatUint(uint64(info.Generation), 10),
+			strconv.FormatFloat(info.ElapsedTime.Seconds(), 'f', -1, 64),
+			strconv.FormatFloat(info.Best, 'g', -1, 64),
+			strconv.FormatFloat(info.Mean, 'g', -1, 64),
+			strconv.FormatFloat(info.Median, 'g', -1, 64),
+			strconv.FormatFloat(info.Worst, 'g', -1, 64),
+			strconv.FormatFloat(info.StdDev, 'g', -1, 64),
+			strconv.FormatUint(info.Evaluations, 10),
+			strconv.FormatUint(info.Timeouts, 10),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
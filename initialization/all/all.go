@@ -0,0 +1,16 @@
+/*
+Package all is a convenience package for importing all initialization
+algorithms implemented in this project.
+*/
+package all
+
+import (
+	// Blank importing all algorithms forces the algorithms to register
+	// themselves at runtime saving the trouble of having to import all
+	// algorithms one by one.
+	_ "github.com/stiganik/gap/initialization/bernoulli"
+	_ "github.com/stiganik/gap/initialization/heuristic"
+	_ "github.com/stiganik/gap/initialization/opposition"
+	_ "github.com/stiganik/gap/initialization/stratified"
+	_ "github.com/stiganik/gap/initialization/uniform"
+)
@@ -0,0 +1,51 @@
+/*
+Package bernoulli is the Bernoulli initialization algorithm implementation.
+*/
+package bernoulli
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/stiganik/gap/initialization"
+	"github.com/stiganik/gap/solution"
+)
+
+func init() {
+	initialization.Register(initialization.BERNOULLI, New)
+}
+
+type bernoulli struct {
+	p   float64
+	rnd *rand.Rand
+}
+
+// New creates an instance of the Bernoulli initialization algorithm.
+func New(opts initialization.Options) (initialization.Initializer, error) {
+	if opts.Probability <= 0 || opts.Probability > 1 {
+		return nil, fmt.Errorf("Bernoulli probability out of range: %g", opts.Probability)
+	}
+
+	return &bernoulli{
+		p:   opts.Probability,
+		rnd: opts.Rand(),
+	}, nil
+}
+
+// Initialize sets every bit of every solution with probability p. The bits
+// beyond the solution bit size are left unset.
+func (b *bernoulli) Initialize(pool solution.Pool) error {
+	for i := range pool.Specimens {
+		buf := pool.Specimens[i].Buf
+		for j := range buf {
+			buf[j] = 0
+		}
+		for k := uint(0); k < pool.SpecimenBitSize; k++ {
+			if b.rnd.Float64() < b.p {
+				buf[k/8] |= 1 << (k % 8)
+			}
+		}
+	}
+
+	return nil
+}
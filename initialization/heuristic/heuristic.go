@@ -0,0 +1,47 @@
+/*
+Package heuristic is the user supplied heuristic initialization algorithm
+implementation.
+*/
+package heuristic
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/stiganik/gap/initialization"
+	"github.com/stiganik/gap/solution"
+)
+
+func init() {
+	initialization.Register(initialization.HEURISTIC, New)
+}
+
+type heuristic struct {
+	fn  initialization.HeuristicFn
+	rnd *rand.Rand
+}
+
+// New creates an instance of the heuristic initialization algorithm.
+func New(opts initialization.Options) (initialization.Initializer, error) {
+	if opts.Heuristic == nil {
+		return nil, fmt.Errorf("Heuristic generator missing")
+	}
+
+	return &heuristic{
+		fn:  opts.Heuristic,
+		rnd: opts.Rand(),
+	}, nil
+}
+
+// Initialize creates every solution with the heuristic generator. The padding
+// bits set by the generator are cleared.
+func (h *heuristic) Initialize(pool solution.Pool) error {
+	for i := range pool.Specimens {
+		if err := h.fn(pool.Specimens[i].Buf, h.rnd); err != nil {
+			return fmt.Errorf("Heuristic generator failed: %w", err)
+		}
+		solution.ClearPadding(pool.Specimens[i].Buf, pool.SpecimenBitSize)
+	}
+
+	return nil
+}
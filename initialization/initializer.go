@@ -0,0 +1,122 @@
+/*
+Package initialization is the interface package for all population
+initialization algorithms.
+*/
+package initialization
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/stiganik/gap/solution"
+)
+
+// Algorithm defines a set of supported population initialization algorithms.
+type Algorithm string
+
+const (
+	// The uniform initialization algorithm fills the solutions with
+	// uniformly distributed random bits.
+	UNIFORM Algorithm = "uniform"
+
+	// The Bernoulli initialization algorithm sets every bit of a solution
+	// with the probability given in Params.Probability, e.g. a low
+	// probability creates sparse solutions.
+	BERNOULLI Algorithm = "bernoulli"
+
+	// The stratified initialization algorithm is a Latin hypercube style
+	// initialization for bit strings. Every bit position is set in exactly
+	// half of the solutions, the solutions are chosen at random for every
+	// bit position separately.
+	// https://en.wikipedia.org/wiki/Latin_hypercube_sampling
+	STRATIFIED Algorithm = "stratified"
+
+	// The opposition based initialization algorithm fills the first half of
+	// the pool with uniformly distributed random bits and the second half
+	// with their opposites, i.e. the solutions with all bits flipped.
+	// https://en.wikipedia.org/wiki/Opposition-based_learning
+	OPPOSITION Algorithm = "opposition"
+
+	// The heuristic initialization algorithm creates every solution with
+	// the user supplied generator in Params.Heuristic.
+	HEURISTIC Algorithm = "heuristic"
+)
+
+var syncMutex sync.RWMutex
+var algorithms map[Algorithm]NewFunc
+
+// HeuristicFn generates a solution into buf using the random number generator
+// rnd. The padding bits of buf are cleared after the call.
+type HeuristicFn func(buf []byte, rnd *rand.Rand) error
+
+// Params contains the parameters of the algorithms that need any.
+type Params struct {
+	// Probability is the probability of a bit being set by the BERNOULLI
+	// algorithm. It must be between 0 (exclusive) and 1.
+	Probability float64
+
+	// Heuristic is the generator used by the HEURISTIC algorithm.
+	Heuristic HeuristicFn
+}
+
+// Options contains the settings an algorithm implementation is created with.
+type Options struct {
+	Params
+
+	// Source is the source of random numbers used by the algorithm. If it
+	// is nil, a source seeded with the current time is used.
+	Source rand.Source
+}
+
+// Rand returns a random number generator using the source of the options.
+func (o Options) Rand() *rand.Rand {
+	if o.Source == nil {
+		return rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return rand.New(o.Source)
+}
+
+// NewFunc creates a new instance of the algorithm implementation this function
+// belongs to.
+type NewFunc func(opts Options) (Initializer, error)
+
+// Register registers a new initialization algorithm for use through the
+// Initializer interface.
+func Register(alg Algorithm, new NewFunc) {
+	syncMutex.Lock()
+	defer syncMutex.Unlock()
+
+	if algorithms == nil {
+		algorithms = make(map[Algorithm]NewFunc)
+	}
+	algorithms[alg] = new
+}
+
+// New creates a new instance of the initialization algorithm defined by alg.
+func New(alg Algorithm, opts Options) (Initializer, error) {
+	syncMutex.RLock()
+	defer syncMutex.RUnlock()
+
+	newFn, ok := algorithms[alg]
+	if !ok {
+		return nil, fmt.Errorf("Algorithm not linked: %s", alg)
+	}
+
+	return newFn(opts)
+}
+
+// Initializer is the interface for all initialization algorithms in this
+// project. Initialization algorithms should not be used directly, only through
+// this interface.
+type Initializer interface {
+	// Initialize fills every solution of the pool with an initial value
+	// based on the initialization algorithm chosen.
+	//
+	// The initialization algorithm MUST NOT change the length and/or
+	// capacity of the solution pool or the solutions. The initialization
+	// algorithm MUST leave the padding bits of the solutions unset, see
+	// solution.Pool for the bit order.
+	Initialize(pool solution.Pool) error
+}
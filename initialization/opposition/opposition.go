@@ -0,0 +1,52 @@
+/*
+Package opposition is the opposition based initialization algorithm
+implementation.
+*/
+package opposition
+
+import (
+	"math/rand"
+
+	"github.com/stiganik/gap/initialization"
+	"github.com/stiganik/gap/solution"
+)
+
+func init() {
+	initialization.Register(initialization.OPPOSITION, New)
+}
+
+type opposition struct {
+	rnd *rand.Rand
+}
+
+// New creates an instance of the opposition based initialization algorithm.
+func New(opts initialization.Options) (initialization.Initializer, error) {
+	return &opposition{
+		rnd: opts.Rand(),
+	}, nil
+}
+
+// Initialize fills the first half of the pool with uniformly distributed random
+// bits and the second half with their opposites. If the pool size is odd, the
+// solution in the middle has no opposite. The padding bits are left unset.
+//
+// Solution A: 00101101
+// Solution B: 11010010
+func (o *opposition) Initialize(pool solution.Pool) error {
+	specimens := pool.Specimens
+	half := (len(specimens) + 1) / 2
+	for i := 0; i < half; i++ {
+		if _, err := o.rnd.Read(specimens[i].Buf); err != nil {
+			return err
+		}
+	}
+
+	for i := half; i < len(specimens); i++ {
+		for j, b := range specimens[i-half].Buf {
+			specimens[i].Buf[j] = ^b
+		}
+	}
+
+	pool.ClearPadding()
+	return nil
+}
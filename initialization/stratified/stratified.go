@@ -0,0 +1,70 @@
+/*
+Package stratified is the Latin hypercube style stratified initialization
+algorithm implementation.
+*/
+package stratified
+
+import (
+	"math/rand"
+
+	"github.com/stiganik/gap/initialization"
+	"github.com/stiganik/gap/solution"
+)
+
+func init() {
+	initialization.Register(initialization.STRATIFIED, New)
+}
+
+type stratified struct {
+	rnd  *rand.Rand
+	perm []int
+}
+
+// New creates an instance of the stratified initialization algorithm.
+func New(opts initialization.Options) (initialization.Initializer, error) {
+	return &stratified{
+		rnd: opts.Rand(),
+	}, nil
+}
+
+// Initialize sets every bit position in exactly half of the solutions, chosen
+// at random for every bit position. If the pool size is odd, the bit of the
+// remaining solution is chosen at random. The bits beyond the solution bit
+// size are left unset.
+func (s *stratified) Initialize(pool solution.Pool) error {
+	specimens := pool.Specimens
+	n := len(specimens)
+	if n == 0 {
+		return nil
+	}
+
+	for i := range specimens {
+		for j := range specimens[i].Buf {
+			specimens[i].Buf[j] = 0
+		}
+	}
+
+	if cap(s.perm) < n {
+		s.perm = make([]int, n)
+	}
+	s.perm = s.perm[:n]
+	for i := range s.perm {
+		s.perm[i] = i
+	}
+
+	for k := uint(0); k < pool.SpecimenBitSize; k++ {
+		s.rnd.Shuffle(n, func(i, j int) {
+			s.perm[i], s.perm[j] = s.perm[j], s.perm[i]
+		})
+
+		ones := n / 2
+		if n%2 == 1 && s.rnd.Intn(2) == 1 {
+			ones++
+		}
+		for _, i := range s.perm[:ones] {
+			specimens[i].Buf[k/8] |= 1 << (k % 8)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+/*
+Package uniform is the uniform random initialization algorithm implementation.
+*/
+package uniform
+
+import (
+	"math/rand"
+
+	"github.com/stiganik/gap/initialization"
+	"github.com/stiganik/gap/solution"
+)
+
+func init() {
+	initialization.Register(initialization.UNIFORM, New)
+}
+
+type uniform struct {
+	rnd *rand.Rand
+}
+
+// New creates an instance of the uniform random initialization algorithm.
+func New(opts initialization.Options) (initialization.Initializer, error) {
+	return &uniform{
+		rnd: opts.Rand(),
+	}, nil
+}
+
+// Initialize fills every solution with uniformly distributed random bits. The
+// padding bits are left unset.
+func (u *uniform) Initialize(pool solution.Pool) error {
+	for i := range pool.Specimens {
+		if _, err := u.rnd.Read(pool.Specimens[i].Buf); err != nil {
+			return err
+		}
+	}
+
+	pool.ClearPadding()
+	return nil
+}
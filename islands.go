@@ -0,0 +1,385 @@
+package gap
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/stiganik/gap/rng"
+	"github.com/stiganik/gap/solution"
+)
+
+// Topology defines which islands the solutions migrate to in the island
+// model.
+type Topology uint
+
+const (
+	// TOPOLOGY_RING migrates the solutions of every island to the next
+	// island, the last island migrating to the first one. This is the
+	// default topology.
+	TOPOLOGY_RING Topology = iota
+
+	// TOPOLOGY_FULLY_CONNECTED migrates the solutions of every island to
+	// all of the other islands.
+	TOPOLOGY_FULLY_CONNECTED
+
+	// TOPOLOGY_RANDOM migrates the solutions of every island to a randomly
+	// chosen other island at every migration.
+	TOPOLOGY_RANDOM
+)
+
+var (
+	defaultMigrationInterval = uint(10)
+	defaultMigrationSize     = uint(1)
+)
+
+// IslandModel runs several genetic algorithms, called islands, side by side
+// and periodically migrates the best solutions between them. Every island has
+// its own solution pool, selection and combination algorithms, which keeps the
+// islands from converging on the same solutions too early.
+type IslandModel struct {
+	// Islands are the algorithms run on the islands. The islands may be
+	// configured differently, but they must use the same direction and
+	// solution bit size.
+	// Checkpoints are not supported in the island model.
+	Islands []*Algorithm
+
+	// MigrationInterval is the amount of generations between migrations.
+	// The default value is 10.
+	MigrationInterval uint
+
+	// MigrationSize is the amount of best solutions every island sends
+	// per migration. The migrated solutions replace the worst solutions of
+	// the receiving island. The default value is 1.
+	MigrationSize uint
+
+	// Topology determines which islands the solutions migrate to. The
+	// default value is TOPOLOGY_RING.
+	Topology Topology
+
+	// Seed is the seed the seeds of the islands and the random topology
+	// are derived from. If the value is nil, the current time is used
+	// instead and the islands with a seed of their own use it as is.
+	Seed *int64
+
+	// Observers are notified about the progress of the whole model after
+	// every generation. The observers of the islands are notified about
+	// the progress of their own island.
+	Observers []Observer
+
+	// RecordHistory enables recording the statistics of the whole model
+	// into the History field of the result.
+	RecordHistory bool
+}
+
+func (m *IslandModel) check() error {
+	if len(m.Islands) == 0 {
+		return fmt.Errorf("No islands")
+	}
+	if m.MigrationInterval == 0 {
+		m.MigrationInterval = defaultMigrationInterval
+	}
+	if m.MigrationSize == 0 {
+		m.MigrationSize = defaultMigrationSize
+	}
+	if m.Topology > TOPOLOGY_RANDOM {
+		return fmt.Errorf("Unknown topology: %d", m.Topology)
+	}
+
+	for i, a := range m.Islands {
+		if err := a.check(); err != nil {
+			return fmt.Errorf("Island %d: %w", i, err)
+		}
+		if a.CheckpointPath != "" {
+			return fmt.Errorf("Island %d: Checkpoints not supported", i)
+		}
+		if a.Direction != m.Islands[0].Direction {
+			return fmt.Errorf("Island %d: Direction mismatch", i)
+		}
+		if a.SolutionBitSize != m.Islands[0].SolutionBitSize {
+			return fmt.Errorf("Island %d: Solution size mismatch", i)
+		}
+	}
+	return nil
+}
+
+// islands contains the state of a single run of the island model.
+type islands struct {
+	m    *IslandModel
+	seed int64
+	runs []*run
+	rnd  *rand.Rand
+
+	// best is the best solution of all of the islands found so far, valid
+	// once improved is set.
+	best     solution.Specimen
+	improved bool
+
+	generation uint
+	history    History
+	start      time.Time
+}
+
+// Run runs the islands and retrieves the correctest answer of all of the
+// islands once the goal is reached. The islands are run in lockstep, one
+// generation at a time. The goal applies to the whole model, e.g. the
+// EVALUATIONS goal counts the evaluations of all of the islands.
+func (m *IslandModel) Run(g Goal) (Result, error) {
+	return m.RunContext(context.Background(), g)
+}
+
+// RunContext is like Run, but stops the islands once ctx is done as described
+// in Algorithm.RunContext.
+func (m *IslandModel) RunContext(ctx context.Context, g Goal) (Result, error) {
+	if err := m.check(); err != nil {
+		return Result{}, err
+	}
+
+	s := &islands{m: m, seed: time.Now().UnixNano()}
+	if m.Seed != nil {
+		s.seed = *m.Seed
+	}
+	seeder := rng.NewSource(s.seed)
+
+	for i, a := range m.Islands {
+		seed := seeder.Int63()
+		if m.Seed == nil && a.Seed != nil {
+			seed = *a.Seed
+		}
+
+		r, err := a.newRun(seed)
+		if err != nil {
+			return Result{}, fmt.Errorf("Island %d: %w", i, err)
+		}
+		s.runs = append(s.runs, r)
+	}
+	s.rnd = rand.New(rng.NewSource(seeder.Int63()))
+
+	if err := g.init(ctx, m.Islands[0].Direction, 0); err != nil {
+		return Result{}, err
+	}
+	defer g.finalize()
+
+	return s.loop(&g)
+}
+
+func (s *islands) totalEvaluations() uint64 {
+	var total uint64
+	for _, r := range s.runs {
+		total += r.evaluations
+	}
+	return total
+}
+
+// loop runs the generations of all of the islands until the goal is reached.
+func (s *islands) loop(g *Goal) (Result, error) {
+	s.start = time.Now()
+	for _, r := range s.runs {
+		r.start = s.start
+	}
+
+	for {
+		if g.checkGen(s.generation) || g.checkEvaluations(s.totalEvaluations()) || g.checkTime() {
+			break
+		}
+
+		stop := false
+		for _, r := range s.runs {
+			r.offset = s.totalEvaluations() - r.evaluations
+
+			_, found, rstop, err := r.evaluatePhase(g)
+			if err != nil {
+				return s.result(g), err
+			}
+			if found != nil {
+				g.checkFitness(found.Fitness)
+				r.improve(*found)
+				s.improve(*found)
+				return s.result(g), nil
+			}
+			if rstop {
+				stop = true
+				break
+			}
+		}
+		if stop {
+			s.rankPartial()
+			break
+		}
+
+		if g.checkTerminator(s.rank()) {
+			s.advance()
+			break
+		}
+
+		if (s.generation+1)%s.m.MigrationInterval == 0 {
+			s.migrate()
+		}
+
+		for _, r := range s.runs {
+			if stop, err := r.breedPhase(g); err != nil {
+				return s.result(g), err
+			} else if stop {
+				return s.finish(g)
+			}
+		}
+		s.advance()
+	}
+
+	return s.finish(g)
+}
+
+func (s *islands) advance() {
+	s.generation++
+	for _, r := range s.runs {
+		r.generation++
+	}
+}
+
+func (s *islands) improve(sp solution.Specimen) {
+	d := s.m.Islands[0].Direction
+	if !s.improved || d.Better(sp.Fitness, s.best.Fitness) {
+		s.improved = true
+		s.best.Copy(sp)
+		notifyImprovement(s.m.Observers, sp)
+	}
+}
+
+// rank gathers the statistics of the solutions of all of the islands after
+// they have been ranked and reports them to the observers and the history.
+func (s *islands) rank() GenerationInfo {
+	d := s.m.Islands[0].Direction
+
+	var all solution.Specimens
+	for _, r := range s.runs {
+		all = append(all, r.pools[r.cur].Specimens...)
+	}
+	all.Sort(d)
+	s.improve(all[0])
+
+	info := generationInfo(all, d)
+	info.Generation = s.generation
+	info.ElapsedTime = time.Since(s.start)
+	info.Evaluations = s.totalEvaluations()
+	for _, r := range s.runs {
+		info.Timeouts += r.timeouts
+	}
+	notifyGeneration(s.m.Observers, info)
+	if s.m.RecordHistory {
+		s.history = append(s.history, info)
+	}
+	return info
+}
+
+// rankPartial picks the best solution of the islands after the goal was
+// reached in the middle of a generation.
+func (s *islands) rankPartial() {
+	for _, r := range s.runs {
+		if r.improved {
+			s.improve(r.best)
+		}
+	}
+}
+
+// migrate sends copies of the best solutions of every island to the islands
+// defined by the topology, where they replace the worst solutions. All of the
+// migrants are chosen before any of them are placed, so the migration happens
+// simultaneously on all of the islands.
+func (s *islands) migrate() {
+	n := len(s.runs)
+	if n < 2 {
+		return
+	}
+
+	migrants := make([]solution.Specimens, n)
+	for i, r := range s.runs {
+		specimens := r.pools[r.cur].Specimens
+		k := int(s.m.MigrationSize)
+		if k > len(specimens) {
+			k = len(specimens)
+		}
+		migrants[i] = make(solution.Specimens, k)
+		for j := range migrants[i] {
+			migrants[i][j].Copy(specimens[j])
+		}
+	}
+
+	incoming := make([]solution.Specimens, n)
+	for i := range s.runs {
+		switch s.m.Topology {
+		case TOPOLOGY_RING:
+			incoming[(i+1)%n] = append(incoming[(i+1)%n], migrants[i]...)
+		case TOPOLOGY_FULLY_CONNECTED:
+			for j := range s.runs {
+				if j != i {
+					incoming[j] = append(incoming[j], migrants[i]...)
+				}
+			}
+		case TOPOLOGY_RANDOM:
+			j := s.rnd.Intn(n - 1)
+			if j >= i {
+				j++
+			}
+			incoming[j] = append(incoming[j], migrants[i]...)
+		}
+	}
+
+	for i, r := range s.runs {
+		specimens := r.pools[r.cur].Specimens
+		for j, sp := range incoming[i] {
+			if j >= len(specimens) {
+				break
+			}
+			specimens[len(specimens)-1-j].Copy(sp)
+		}
+		specimens.Sort(r.a.Direction)
+	}
+}
+
+func (s *islands) result(g *Goal) Result {
+	ret := Result{
+		StopReason:  g.reason,
+		ElapsedTime: time.Since(s.start),
+		Generation:  s.generation,
+		Evaluations: s.totalEvaluations(),
+		Seed:        s.seed,
+		History:     s.history,
+	}
+	if s.improved {
+		ret.Solution.Copy(s.best)
+	} else {
+		ret.Solution.Fitness = math.NaN()
+	}
+
+	// The hall of fame of the model is merged from the halls of fame of the
+	// islands and is as large as the largest one of them.
+	var hof *hallOfFame
+	for _, r := range s.runs {
+		if r.hof != nil && (hof == nil || r.hof.size > hof.size) {
+			hof = newHallOfFame(uint(r.hof.size), r.hof.unique, r.a.Direction)
+		}
+	}
+
+	for _, r := range s.runs {
+		island := r.result(r.best, g)
+		ret.CacheHits += island.CacheHits
+		ret.CacheMisses += island.CacheMisses
+		ret.Failures += island.Failures
+		ret.Timeouts += island.Timeouts
+		ret.Islands = append(ret.Islands, island)
+		hof.offerSorted(island.HallOfFame)
+	}
+	ret.HallOfFame = hof.copies()
+	return ret
+}
+
+// finish returns the result of a run that was stopped by the goal.
+func (s *islands) finish(g *Goal) (Result, error) {
+	ret := s.result(g)
+	if cerr := g.canceled(); cerr != nil {
+		return ret, fmt.Errorf("%w: %w", ErrCanceled, cerr)
+	}
+	return ret, nil
+}
@@ -0,0 +1,102 @@
+package gap
+
+import (
+	"math"
+	"time"
+
+	"github.com/stiganik/gap/solution"
+)
+
+// GenerationInfo contains statistics about a single generation of the
+// algorithm. The statistics are gathered once the solutions of the generation
+// have been evaluated and ranked.
+type GenerationInfo struct {
+	// Generation is the number of the generation, starting from zero.
+	Generation uint
+
+	// ElapsedTime is the time elapsed since the start of the algorithm.
+	ElapsedTime time.Duration
+
+	// Direction is the optimization direction of the algorithm.
+	Direction solution.Direction
+
+	// Best, Mean, Median, Worst and StdDev describe the fitness of the
+	// solutions in the generation. StdDev is the population standard
+	// deviation.
+	Best   float64
+	Mean   float64
+	Median float64
+	Worst  float64
+	StdDev float64
+
+	// Evaluations is the amount of times the fitness function has been
+	// called since the start of the algorithm.
+	Evaluations uint64
+
+	// Timeouts is the amount of evaluations that have timed out since the
+	// start of the algorithm.
+	Timeouts uint64
+}
+
+// Observer is the interface for monitoring the progress of the algorithm. The
+// methods are called synchronously from the goroutine running the algorithm,
+// so slow observers slow down the algorithm. An observer can stop the algorithm
+// early by cancelling the context passed to Algorithm.RunContext.
+type Observer interface {
+	// OnGeneration is called after every generation has been evaluated and
+	// ranked.
+	OnGeneration(info GenerationInfo)
+
+	// OnImprovement is called every time a solution better than any of the
+	// previous solutions is found. The specimen is a copy and can be
+	// retained by the observer.
+	OnImprovement(s solution.Specimen)
+}
+
+// generationInfo gathers the statistics of a pool sorted from best to worst
+// according to the direction d.
+func generationInfo(specimens solution.Specimens, d solution.Direction) GenerationInfo {
+	info := GenerationInfo{Direction: d}
+	if len(specimens) == 0 {
+		return info
+	}
+
+	n := len(specimens)
+
+	var total float64
+	for _, s := range specimens {
+		total += s.Fitness
+	}
+	info.Mean = total / float64(n)
+
+	var variance float64
+	for _, s := range specimens {
+		d := s.Fitness - info.Mean
+		variance += d * d
+	}
+	info.StdDev = math.Sqrt(variance / float64(n))
+
+	if n%2 == 0 {
+		info.Median = (specimens[n/2-1].Fitness + specimens[n/2].Fitness) / 2
+	} else {
+		info.Median = specimens[n/2].Fitness
+	}
+
+	info.Best = specimens[0].Fitness
+	info.Worst = specimens[n-1].Fitness
+	return info
+}
+
+func notifyGeneration(observers []Observer, info GenerationInfo) {
+	for _, o := range observers {
+		o.OnGeneration(info)
+	}
+}
+
+func notifyImprovement(observers []Observer, s solution.Specimen) {
+	for _, o := range observers {
+		var c solution.Specimen
+		c.Copy(s)
+		o.OnImprovement(c)
+	}
+}
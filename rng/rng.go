@@ -0,0 +1,49 @@
+/*
+Package rng implements a random number source with a state that can be saved
+and restored. This makes it possible to continue a random number sequence
+exactly where it was left off, e.g. after restarting the process.
+*/
+package rng
+
+// Source is a random number source implementing the SplitMix64 generator.
+// The whole state of the generator is a single uint64 value. Source
+// implements the math/rand Source64 interface. Like the sources of the
+// math/rand package, it is not safe for concurrent use.
+type Source struct {
+	state uint64
+}
+
+// NewSource creates a new source seeded with the given value.
+func NewSource(seed int64) *Source {
+	return &Source{state: uint64(seed)}
+}
+
+// Seed uses the provided seed value to initialize the source to a
+// deterministic state.
+func (s *Source) Seed(seed int64) {
+	s.state = uint64(seed)
+}
+
+// Uint64 returns a pseudo-random 64-bit value as a uint64.
+func (s *Source) Uint64() uint64 {
+	s.state += 0x9e3779b97f4a7c15
+	z := s.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Int63 returns a non-negative pseudo-random 63-bit integer as an int64.
+func (s *Source) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// State returns the current state of the source.
+func (s *Source) State() uint64 {
+	return s.state
+}
+
+// SetState restores a state previously returned by State.
+func (s *Source) SetState(state uint64) {
+	s.state = state
+}
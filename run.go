@@ -0,0 +1,394 @@
+package gap
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/stiganik/gap/combination"
+	"github.com/stiganik/gap/initialization"
+	"github.com/stiganik/gap/rng"
+	"github.com/stiganik/gap/selection"
+	"github.com/stiganik/gap/solution"
+)
+
+// run contains the complete state of a single run of the algorithm. Keeping
+// all of it in one place makes it possible to save and restore the run.
+type run struct {
+	a    *Algorithm
+	seed int64
+
+	// pools are the two solution buffers the generations alternate
+	// between. cur is the index of the pool holding the current
+	// generation.
+	pools [2]solution.Pool
+	cur   int
+
+	sel       selection.Selector
+	selSrc    *rng.Source
+	combiners []combination.Combiner
+	combSrcs  []*rng.Source
+
+	// offspring is the pool the offspring are bred in and parents holds
+	// copies of their parents in the steady state mode. rnd is used for
+	// choosing the solutions to replace.
+	offspring solution.Pool
+	parents   [][]byte
+	rnd       *rand.Rand
+	rndSrc    *rng.Source
+
+	// cache is the fitness cache, nil if caching is disabled. owner and
+	// jobs are the buffers used for distributing the evaluation.
+	cache *fitnessCache
+	owner []int
+	jobs  []int
+
+	// best is the best solution found so far, valid once improved is set.
+	best     solution.Specimen
+	improved bool
+
+	// worst is the worst fitness found so far, valid once worstKnown is
+	// set. failures and timeouts are the amounts of solutions that failed
+	// to be evaluated and whose evaluation timed out.
+	worst      float64
+	worstKnown bool
+	failures   uint64
+	timeouts   uint64
+
+	// hof is the hall of fame, nil if it is disabled. elite is the amount
+	// of elite solutions carried over unchanged from the previous
+	// generation, which have already been offered to the hall of fame.
+	hof   *hallOfFame
+	elite int
+
+	generation  uint
+	evaluations uint64
+	history     History
+
+	// offset is the amount of evaluations made outside of the run that
+	// count towards the evaluation goal, e.g. by other islands.
+	offset uint64
+
+	// elapsed is the time the run had been going on before it was last
+	// started or resumed at start.
+	elapsed time.Duration
+	start   time.Time
+
+	// resumed is the generation the run was started or resumed from.
+	resumed uint
+}
+
+// newRun creates the pools and operators of a new run. Independent random
+// number streams are derived from seed for initializing the pool, the selector
+// and every combiner, which makes runs with the same seed reproducible.
+func (a *Algorithm) newRun(seed int64) (*run, error) {
+	r := &run{a: a, seed: seed}
+	for i := range r.pools {
+		r.pools[i] = solution.NewPool(a.SolutionPoolSize, a.SolutionBitSize)
+		r.pools[i].Direction = a.Direction
+	}
+
+	seeder := rng.NewSource(seed)
+
+	initializer, err := initialization.New(a.InitializationAlgorithm, initialization.Options{
+		Params: a.InitializationParams,
+		Source: rng.NewSource(seeder.Int63()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = initializer.Initialize(r.pools[0]); err != nil {
+		return nil, err
+	}
+	if err = r.pools[0].CheckPadding(); err != nil {
+		return nil, fmt.Errorf("Initialization algorithm %s: %w", a.InitializationAlgorithm, err)
+	}
+	for i, genome := range a.initialPopulation() {
+		copy(r.pools[0].Specimens[i].Buf, genome)
+	}
+
+	// The steady state mode keeps the best solutions in the pool by itself,
+	// elitism only applies to the generational mode.
+	elitism := *a.Elitism
+	if a.Mode == STEADY_STATE {
+		elitism = 0
+	}
+	r.elite = int((float64(elitism) / float64(100)) * float64(a.SolutionPoolSize))
+
+	r.selSrc = rng.NewSource(seeder.Int63())
+	r.sel, err = selection.New(a.SelectionAlgorithm, selection.Options{
+		Params:  a.SelectionParams,
+		Elitism: elitism,
+		Source:  r.selSrc,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, comb := range a.CombinationAlgorithms {
+		src := rng.NewSource(seeder.Int63())
+		c, err := combination.New(comb, combination.Options{
+			Elitism: elitism,
+			Source:  src,
+		})
+		if err != nil {
+			return nil, err
+		}
+		r.combiners = append(r.combiners, c)
+		r.combSrcs = append(r.combSrcs, src)
+	}
+
+	r.rndSrc = rng.NewSource(seeder.Int63())
+	r.rnd = rand.New(r.rndSrc)
+	if a.Mode == STEADY_STATE {
+		r.offspring = solution.NewPool(a.Offspring, a.SolutionBitSize)
+		r.offspring.Direction = a.Direction
+		r.parents = make([][]byte, a.Offspring)
+		for i := range r.parents {
+			r.parents[i] = make([]byte, r.offspring.SpecimenByteSize)
+		}
+	}
+
+	r.owner = make([]int, a.SolutionPoolSize)
+	if a.CacheSize > 0 {
+		r.cache = newFitnessCache(a.CacheSize)
+	}
+	if a.HallOfFameSize > 0 {
+		r.hof = newHallOfFame(a.HallOfFameSize, a.HallOfFameUnique, a.Direction)
+	}
+
+	return r, nil
+}
+
+func (r *run) elapsedTime() time.Duration {
+	return r.elapsed + time.Since(r.start)
+}
+
+func (r *run) result(s solution.Specimen, g *Goal) Result {
+	ret := Result{
+		StopReason:  g.reason,
+		ElapsedTime: r.elapsedTime(),
+		Generation:  r.generation,
+		Evaluations: r.evaluations,
+		Failures:    r.failures,
+		Timeouts:    r.timeouts,
+		Seed:        r.seed,
+		History:     r.history,
+		HallOfFame:  r.hof.copies(),
+	}
+	if r.cache != nil {
+		ret.CacheHits = r.cache.hits
+		ret.CacheMisses = r.cache.misses
+	}
+	if r.improved {
+		ret.Solution.Copy(s)
+	} else {
+		ret.Solution.Fitness = math.NaN()
+	}
+	return ret
+}
+
+// improve records s as the best solution found so far if it is better than all
+// of the previous ones and notifies the observers about it.
+func (r *run) improve(s solution.Specimen) {
+	if !r.improved || r.a.Direction.Better(s.Fitness, r.best.Fitness) {
+		r.improved = true
+		r.best.Copy(s)
+		notifyImprovement(r.a.Observers, s)
+	}
+}
+
+// reached stops the algorithm with the specimen s that reached the fitness
+// goal.
+func (r *run) reached(s solution.Specimen, g *Goal) Result {
+	g.checkFitness(s.Fitness)
+	r.improve(s)
+	return r.result(s, g)
+}
+
+// rankPartial ranks the evaluated part of a pool after the goal was reached in
+// the middle of a generation.
+func (r *run) rankPartial(evaluated solution.Specimens) {
+	if len(evaluated) == 0 {
+		return
+	}
+
+	evaluated.Sort(r.a.Direction)
+	r.improve(evaluated[0])
+}
+
+// rank ranks the evaluated pool of the current generation and reports the
+// statistics of the generation to the observers and the history.
+func (r *run) rank(specimens solution.Specimens) GenerationInfo {
+	a := r.a
+	specimens.Sort(a.Direction)
+	r.improve(specimens[0])
+
+	info := generationInfo(specimens, a.Direction)
+	info.Generation = r.generation
+	info.ElapsedTime = r.elapsedTime()
+	info.Evaluations = r.evaluations
+	info.Timeouts = r.timeouts
+	notifyGeneration(a.Observers, info)
+	if a.RecordHistory {
+		r.history = append(r.history, info)
+	}
+	return info
+}
+
+// finish returns the result of a run that was stopped by the goal.
+func (r *run) finish(g *Goal) (Result, error) {
+	ret := r.result(r.best, g)
+	if cerr := g.canceled(); cerr != nil {
+		return ret, fmt.Errorf("%w: %w", ErrCanceled, cerr)
+	}
+	return ret, nil
+}
+
+// totalEvaluations returns the amount of evaluations counted towards the goal.
+func (r *run) totalEvaluations() uint64 {
+	return r.offset + r.evaluations
+}
+
+// evaluatePhase evaluates and ranks the solutions of the current generation.
+// If a solution reaches the fitness goal it is returned in found. stop is set
+// if the goal was reached in the middle of the generation.
+func (r *run) evaluatePhase(g *Goal) (info GenerationInfo, found *solution.Specimen, stop bool, err error) {
+	pool := r.pools[r.cur]
+	if r.a.Mode == STEADY_STATE && r.generation > 0 {
+		if found, stop, err = r.breedSteady(g); found != nil || stop || err != nil {
+			return
+		}
+	} else {
+		i, finished, calls, eerr := r.evaluate(pool.Specimens, g)
+		r.evaluations += calls
+		if eerr != nil {
+			return info, nil, true, eerr
+		}
+		if i >= 0 {
+			return info, &pool.Specimens[i], true, nil
+		}
+
+		// The evaluation may have been cut short, in which case only
+		// the evaluated part of the pool can be ranked.
+		if g.checkTime() {
+			r.rankPartial(finished)
+			return info, nil, true, nil
+		}
+
+		// The evaluation budget ran out in the middle of the
+		// generation.
+		if len(finished) < len(pool.Specimens) {
+			r.rankPartial(finished)
+			g.checkEvaluations(r.totalEvaluations())
+			return info, nil, true, nil
+		}
+	}
+
+	return r.rank(pool.Specimens), nil, false, nil
+}
+
+// breedPhase breeds the solutions of the next generation from the ranked
+// solutions of the current generation. The steady state mode breeds its
+// solutions while evaluating them, so there is nothing left to do for it.
+func (r *run) breedPhase(g *Goal) (stop bool, err error) {
+	if r.a.Mode == STEADY_STATE {
+		return g.checkTime(), nil
+	}
+
+	curPool := r.pools[r.cur]
+	otherPool := r.pools[1-r.cur]
+
+	if g.checkTime() {
+		return true, nil
+	}
+
+	if err = r.selectInto(curPool, otherPool); err != nil {
+		return
+	}
+
+	if g.checkTime() {
+		return true, nil
+	}
+
+	if err = r.combine(otherPool); err != nil {
+		return
+	}
+
+	if g.checkTime() {
+		return true, nil
+	}
+
+	// Switch buffers and repeat genetic algorithm
+	r.cur = 1 - r.cur
+	return false, nil
+}
+
+// selectInto selects solutions from poolA into poolB with the selection
+// algorithm, letting it know the current generation if it depends on it.
+func (r *run) selectInto(poolA, poolB solution.Pool) error {
+	if ga, ok := r.sel.(selection.GenerationAware); ok {
+		ga.SetGeneration(r.generation)
+	}
+	return r.sel.Select(poolA, poolB)
+}
+
+// combine applies the combination algorithms to the pool and checks that they
+// left the padding bits of the solutions unset.
+func (r *run) combine(pool solution.Pool) error {
+	for i, combiner := range r.combiners {
+		if err := combiner.Combine(pool); err != nil {
+			return err
+		}
+		if err := pool.CheckPadding(); err != nil {
+			return fmt.Errorf("Combination algorithm %s: %w", r.a.CombinationAlgorithms[i], err)
+		}
+	}
+	return nil
+}
+
+// loop runs the generations of the algorithm until the goal is reached.
+func (r *run) loop(g *Goal) (Result, error) {
+	r.start = time.Now()
+	r.resumed = r.generation
+	for {
+		if g.checkGen(r.generation) || g.checkEvaluations(r.totalEvaluations()) || g.checkTime() {
+			break
+		}
+
+		if r.checkpointDue() {
+			if err := r.checkpoint(g); err != nil {
+				return r.result(r.best, g), err
+			}
+		}
+
+		info, found, stop, err := r.evaluatePhase(g)
+		if err != nil {
+			return r.result(r.best, g), err
+		}
+		if found != nil {
+			return r.reached(*found, g), nil
+		}
+		if stop {
+			break
+		}
+
+		if g.checkTerminator(info) {
+			// The generation is complete, there is no need to breed
+			// the next one.
+			r.generation++
+			break
+		}
+
+		if stop, err = r.breedPhase(g); err != nil {
+			return r.result(r.best, g), err
+		}
+		if stop {
+			break
+		}
+		r.generation++
+	}
+
+	return r.finish(g)
+}
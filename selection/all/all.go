@@ -8,5 +8,10 @@ import (
 	// Blank importing all algorithms forces the algorithms to register
 	// themselves at runtime saving the trouble of having to import all
 	// algorithms one by one.
+	_ "github.com/stiganik/gap/selection/boltzmann"
+	_ "github.com/stiganik/gap/selection/rank"
 	_ "github.com/stiganik/gap/selection/scx"
+	_ "github.com/stiganik/gap/selection/sus"
+	_ "github.com/stiganik/gap/selection/tournament"
+	_ "github.com/stiganik/gap/selection/truncation"
 )
@@ -0,0 +1,186 @@
+/*
+Package boltzmann is the Boltzmann selection algorithm implementation.
+*/
+package boltzmann
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/stiganik/gap/selection"
+	"github.com/stiganik/gap/solution"
+)
+
+const (
+	defaultTemperature            = 1.0
+	defaultCooling                = selection.COOLING_EXPONENTIAL
+	defaultExponentialRate        = 0.95
+	defaultLogarithmicRate        = 1.0
+	defaultLinearRateDivisor      = 100.0
+	defaultMinTemperatureFraction = 0.001
+)
+
+func init() {
+	selection.Register(selection.BOLTZMANN, New)
+}
+
+type boltzmann struct {
+	elitism     uint
+	rnd         *rand.Rand
+	t0          float64
+	min         float64
+	rate        float64
+	temperature func(generation uint) float64
+	generation  uint
+	cumulative  []float64
+}
+
+// New creates an instance of the Boltzmann selection algorithm.
+func New(opts selection.Options) (selection.Selector, error) {
+	b := &boltzmann{
+		elitism: opts.Elitism,
+		rnd:     opts.Rand(),
+		t0:      opts.Temperature,
+		min:     opts.MinTemperature,
+		rate:    opts.CoolingRate,
+	}
+	if b.t0 == 0 {
+		b.t0 = defaultTemperature
+	}
+	if b.t0 < 0 {
+		return nil, fmt.Errorf("Temperature out of range: %g", b.t0)
+	}
+	if b.min == 0 {
+		b.min = b.t0 * defaultMinTemperatureFraction
+	}
+	if b.min < 0 {
+		return nil, fmt.Errorf("Minimum temperature out of range: %g", b.min)
+	}
+
+	if opts.TemperatureFn != nil {
+		b.temperature = opts.TemperatureFn
+		return b, nil
+	}
+
+	cooling := opts.Cooling
+	if cooling == "" {
+		cooling = defaultCooling
+	}
+	switch cooling {
+	case selection.COOLING_LINEAR:
+		if b.rate == 0 {
+			b.rate = b.t0 / defaultLinearRateDivisor
+		}
+		b.temperature = b.linear
+	case selection.COOLING_EXPONENTIAL:
+		if b.rate == 0 {
+			b.rate = defaultExponentialRate
+		}
+		if b.rate >= 1 {
+			return nil, fmt.Errorf("Exponential cooling rate out of range: %g", b.rate)
+		}
+		b.temperature = b.exponential
+	case selection.COOLING_LOGARITHMIC:
+		if b.rate == 0 {
+			b.rate = defaultLogarithmicRate
+		}
+		b.temperature = b.logarithmic
+	default:
+		return nil, fmt.Errorf("Unknown cooling schedule: %s", cooling)
+	}
+	if b.rate < 0 {
+		return nil, fmt.Errorf("Cooling rate out of range: %g", b.rate)
+	}
+	return b, nil
+}
+
+func (b *boltzmann) linear(generation uint) float64 {
+	return b.t0 - b.rate*float64(generation)
+}
+
+func (b *boltzmann) exponential(generation uint) float64 {
+	return b.t0 * math.Pow(b.rate, float64(generation))
+}
+
+func (b *boltzmann) logarithmic(generation uint) float64 {
+	return b.t0 / (1 + b.rate*math.Log1p(float64(generation)))
+}
+
+// SetGeneration sets the generation the temperature is calculated for.
+func (b *boltzmann) SetGeneration(generation uint) {
+	b.generation = generation
+}
+
+// weigh calculates the cumulative selection weights of the solutions of the
+// pool and returns their total. The weights are calculated relative to the
+// best fitness in the pool, so the best solution has a weight of 1 and the
+// weights can not overflow.
+func (b *boltzmann) weigh(pool solution.Pool) float64 {
+	specimens := pool.Specimens
+	if cap(b.cumulative) < len(specimens) {
+		b.cumulative = make([]float64, len(specimens))
+	}
+	b.cumulative = b.cumulative[:len(specimens)]
+
+	t := b.temperature(b.generation)
+	if t < b.min || math.IsNaN(t) {
+		t = b.min
+	}
+
+	best := specimens[0].Fitness
+	for _, sol := range specimens {
+		if pool.Direction.Better(sol.Fitness, best) {
+			best = sol.Fitness
+		}
+	}
+
+	var sum float64
+	for i, sol := range specimens {
+		d := sol.Fitness - best
+		if pool.Direction == solution.MINIMIZE {
+			d = best - sol.Fitness
+		}
+		// All of the solutions may share an infinitely bad fitness,
+		// e.g. if all of them failed to be evaluated.
+		if math.IsNaN(d) {
+			d = 0
+		}
+		sum += math.Exp(d / t)
+		b.cumulative[i] = sum
+	}
+	return sum
+}
+
+// Select selects a solution from poolA with probability proportionate to
+// exp(f/T) and deposits the solution in poolB. This process is repeated until
+// poolB is full. The temperature T is given by the cooling schedule for the
+// current generation and never falls below the minimum temperature.
+func (b *boltzmann) Select(poolA, poolB solution.Pool) error {
+	specimensA := poolA.Specimens
+	specimensB := poolB.Specimens
+	if len(specimensA) == 0 {
+		return nil
+	}
+
+	total := b.weigh(poolA)
+
+	elite := uint((float64(b.elitism) / float64(100)) * float64(len(specimensA)))
+	for i := range specimensB {
+		if uint(i) < elite {
+			specimensB[i].Fitness = specimensA[i].Fitness
+			copy(specimensB[i].Buf, specimensA[i].Buf)
+			continue
+		}
+
+		j := sort.SearchFloat64s(b.cumulative, b.rnd.Float64()*total)
+		if j >= len(specimensA) {
+			j = len(specimensA) - 1
+		}
+		specimensB[i].Fitness = specimensA[j].Fitness
+		copy(specimensB[i].Buf, specimensA[j].Buf)
+	}
+
+	return nil
+}
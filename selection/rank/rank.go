@@ -0,0 +1,143 @@
+/*
+Package rank is the linear and exponential rank selection algorithm
+implementation.
+*/
+package rank
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/stiganik/gap/selection"
+	"github.com/stiganik/gap/solution"
+)
+
+const (
+	defaultLinearPressure      = 1.5
+	defaultExponentialPressure = 3.0
+)
+
+func init() {
+	selection.Register(selection.RANK_LINEAR, NewLinear)
+	selection.Register(selection.RANK_EXPONENTIAL, NewExponential)
+}
+
+type rank struct {
+	elitism  uint
+	pressure float64
+	rnd      *rand.Rand
+
+	// weigh returns the selection weight of the solution at index i of a
+	// sorted pool of n solutions.
+	weigh func(i, n int, pressure float64) float64
+
+	// cumulative holds the cumulative weights of the pool size they were
+	// calculated for.
+	cumulative []float64
+}
+
+// NewLinear creates an instance of the linear rank selection algorithm.
+func NewLinear(opts selection.Options) (selection.Selector, error) {
+	pressure := opts.Pressure
+	if pressure == 0 {
+		pressure = defaultLinearPressure
+	}
+	if pressure < 1 || pressure > 2 {
+		return nil, fmt.Errorf("Linear rank pressure out of range: %g", pressure)
+	}
+
+	return &rank{
+		elitism:  opts.Elitism,
+		pressure: pressure,
+		rnd:      opts.Rand(),
+		weigh:    linear,
+	}, nil
+}
+
+// NewExponential creates an instance of the exponential rank selection
+// algorithm.
+func NewExponential(opts selection.Options) (selection.Selector, error) {
+	pressure := opts.Pressure
+	if pressure == 0 {
+		pressure = defaultExponentialPressure
+	}
+	if pressure < 0 {
+		return nil, fmt.Errorf("Exponential rank pressure out of range: %g", pressure)
+	}
+
+	return &rank{
+		elitism:  opts.Elitism,
+		pressure: pressure,
+		rnd:      opts.Rand(),
+		weigh:    exponential,
+	}, nil
+}
+
+// linear weighs the solutions so that the best one is selected pressure times
+// and the worst one 2-pressure times on average.
+func linear(i, n int, pressure float64) float64 {
+	if n == 1 {
+		return 1
+	}
+	return pressure - 2*(pressure-1)*float64(i)/float64(n-1)
+}
+
+// exponential weighs the solutions so that the weight decreases by a factor of
+// exp(pressure) from the best solution to the worst one.
+func exponential(i, n int, pressure float64) float64 {
+	if n == 1 {
+		return 1
+	}
+	return math.Exp(-pressure * float64(i) / float64(n-1))
+}
+
+// prepare calculates the cumulative weights for a pool of n solutions. The
+// weights only depend on the pool size, so they are reused while it stays the
+// same.
+func (r *rank) prepare(n int) {
+	if len(r.cumulative) == n {
+		return
+	}
+
+	r.cumulative = make([]float64, n)
+	var sum float64
+	for i := range r.cumulative {
+		sum += r.weigh(i, n, r.pressure)
+		r.cumulative[i] = sum
+	}
+}
+
+// Select selects a solution from poolA with probability depending only on its
+// rank and deposits the solution in poolB. This process is repeated until
+// poolB is full. The rank of a solution is its index in poolA, which requires
+// poolA to be sorted from best to worst.
+func (r *rank) Select(poolA, poolB solution.Pool) error {
+	specimensA := poolA.Specimens
+	specimensB := poolB.Specimens
+	if len(specimensA) == 0 {
+		return nil
+	}
+
+	r.prepare(len(specimensA))
+	total := r.cumulative[len(r.cumulative)-1]
+
+	elite := uint((float64(r.elitism) / float64(100)) * float64(len(specimensA)))
+	for i := range specimensB {
+		if uint(i) < elite {
+			specimensB[i].Fitness = specimensA[i].Fitness
+			copy(specimensB[i].Buf, specimensA[i].Buf)
+			continue
+		}
+
+		j := sort.SearchFloat64s(r.cumulative, r.rnd.Float64()*total)
+		if j >= len(specimensA) {
+			j = len(specimensA) - 1
+		}
+		specimensB[i].Fitness = specimensA[j].Fitness
+		copy(specimensB[i].Buf, specimensA[j].Buf)
+	}
+
+	return nil
+}
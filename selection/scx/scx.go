@@ -5,7 +5,6 @@ package scx
 
 import (
 	"math/rand"
-	"time"
 
 	"github.com/stiganik/gap/selection"
 	"github.com/stiganik/gap/solution"
@@ -18,29 +17,32 @@ func init() {
 type scx struct {
 	elitism uint
 	rnd     *rand.Rand
+	weights []float64
 }
 
 // New creates an instance of the fitness proportionate selection algorithm.
-func New(elitism uint) (selection.Selector, error) {
+func New(opts selection.Options) (selection.Selector, error) {
 	scx := &scx{
-		elitism: elitism,
-		rnd:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		elitism: opts.Elitism,
+		rnd:     opts.Rand(),
 	}
 	return scx, nil
 }
 
 // Select selects a solution from poolA with probability
-// P(solution.fitness / poolA.totalFitness) and deposits the solution in poolB.
-// This process is repeated until poolB is full.
+// P(solution.weight / poolA.totalWeight) and deposits the solution in poolB.
+// This process is repeated until poolB is full. If all of the weights are zero
+// the solutions are selected with equal probability.
 func (s *scx) Select(poolA, poolB solution.Pool) error {
 	specimensA := poolA.Specimens
 	specimensB := poolB.Specimens
-
-	var totalFitness uint
-	for _, sol := range specimensA {
-		totalFitness += sol.Fitness
+	if len(specimensA) == 0 {
+		return nil
 	}
 
+	var totalWeight float64
+	s.weights, totalWeight = selection.FitnessWeights(poolA, s.weights)
+
 	elite := uint((float64(s.elitism) / float64(100)) * float64(len(specimensA)))
 	for i := range specimensB {
 		if uint(i) < elite {
@@ -49,11 +51,18 @@ func (s *scx) Select(poolA, poolB solution.Pool) error {
 			continue
 		}
 
-		r := uint(s.rnd.Float32() * float32(totalFitness)) // Generate value between 0 and totalFitness
-		var sum uint
-		for _, el := range specimensA {
-			sum += el.Fitness
-			if r <= sum {
+		if totalWeight <= 0 {
+			el := specimensA[s.rnd.Intn(len(specimensA))]
+			specimensB[i].Fitness = el.Fitness
+			copy(specimensB[i].Buf, el.Buf)
+			continue
+		}
+
+		r := s.rnd.Float64() * totalWeight // Generate value between 0 and totalWeight
+		var sum float64
+		for j, el := range specimensA {
+			sum += s.weights[j]
+			if r < sum || j == len(specimensA)-1 {
 				specimensB[i].Fitness = el.Fitness
 				copy(specimensB[i].Buf, el.Buf)
 				break
@@ -5,7 +5,10 @@ package selection
 
 import (
 	"fmt"
+	"math"
+	"math/rand"
 	"sync"
+	"time"
 
 	"github.com/stiganik/gap/solution"
 )
@@ -19,15 +22,139 @@ const (
 	// fitness of the solution pool.
 	// https://en.wikipedia.org/wiki/Fitness_proportionate_selection
 	SCX Algorithm = "scx"
+
+	// Tournament selection algorithm. Selects the best of
+	// Params.TournamentSize randomly chosen solutions. In a stochastic
+	// tournament the best contestant wins with probability
+	// Params.WinProbability, the second best with probability p*(1-p) and
+	// so on.
+	// https://en.wikipedia.org/wiki/Tournament_selection
+	TOURNAMENT Algorithm = "tournament"
+
+	// Linear rank selection algorithm. Selects solutions with probability
+	// decreasing linearly with their rank in the sorted solution pool. The
+	// expected amount of copies of the best solution is Params.Pressure.
+	// https://en.wikipedia.org/wiki/Selection_(genetic_algorithm)#Rank_selection
+	RANK_LINEAR Algorithm = "rank_linear"
+
+	// Exponential rank selection algorithm. Selects solutions with
+	// probability decreasing exponentially with their rank in the sorted
+	// solution pool. The best solution is exp(Params.Pressure) times as
+	// likely to be selected as the worst one.
+	RANK_EXPONENTIAL Algorithm = "rank_exponential"
+
+	// Stochastic universal sampling selection algorithm. Selects solutions
+	// with probability proportionate to their fitness like SCX, but with a
+	// single spin of evenly spaced pointers, so the amount of copies of
+	// every solution is as close to its expected amount as possible.
+	// https://en.wikipedia.org/wiki/Stochastic_universal_sampling
+	SUS Algorithm = "sus"
+
+	// Truncation selection algorithm. Only the best Params.Truncation
+	// percent of the sorted solution pool are selected, every one of them
+	// the same amount of times.
+	// https://en.wikipedia.org/wiki/Truncation_selection
+	TRUNCATION Algorithm = "truncation"
+
+	// Boltzmann selection algorithm. Selects solutions with probability
+	// proportionate to exp(f/T), where f is the fitness of the solution and
+	// T is the temperature. The temperature decreases over the generations
+	// according to a cooling schedule, which increases the selection
+	// pressure as the algorithm progresses.
+	// https://en.wikipedia.org/wiki/Selection_(genetic_algorithm)#Boltzmann_selection
+	BOLTZMANN Algorithm = "boltzmann"
+)
+
+// Cooling defines a set of supported cooling schedules of the BOLTZMANN
+// selection algorithm. T0 is the initial temperature, r the cooling rate and g
+// the generation number.
+type Cooling string
+
+const (
+	// Linear cooling schedule, T = T0 - r*g. The default cooling rate is
+	// T0/100.
+	COOLING_LINEAR Cooling = "linear"
+
+	// Exponential cooling schedule, T = T0 * r^g. The cooling rate must be
+	// between 0 and 1, the default value is 0.95.
+	COOLING_EXPONENTIAL Cooling = "exponential"
+
+	// Logarithmic cooling schedule, T = T0 / (1 + r*ln(1+g)). The default
+	// cooling rate is 1.
+	COOLING_LOGARITHMIC Cooling = "logarithmic"
 )
 
 var syncMutex sync.RWMutex
 var algorithms map[Algorithm]NewFunc
 
+// Params contains the parameters of the algorithms that need any. The zero
+// value of every parameter selects its default value.
+type Params struct {
+	// TournamentSize is the amount of contestants in a TOURNAMENT. The
+	// default value is 2.
+	TournamentSize uint
+
+	// WinProbability is the probability of the best contestant winning a
+	// TOURNAMENT. It must be between 0 (exclusive) and 1. The default value
+	// is 1, i.e. the best contestant always wins.
+	WinProbability float64
+
+	// Pressure is the selection pressure of RANK_LINEAR and
+	// RANK_EXPONENTIAL. For RANK_LINEAR it must be between 1 and 2 and the
+	// default value is 1.5. For RANK_EXPONENTIAL it must not be negative
+	// and the default value is 3.
+	Pressure float64
+
+	// Truncation is the percentage of the best solutions selected by
+	// TRUNCATION. It must not be larger than 100, at least one solution is
+	// always selected. The default value is 50.
+	Truncation uint
+
+	// Temperature is the initial temperature of BOLTZMANN. The default
+	// value is 1.
+	Temperature float64
+
+	// Cooling is the cooling schedule of BOLTZMANN. The default value is
+	// COOLING_EXPONENTIAL.
+	Cooling Cooling
+
+	// CoolingRate is the cooling rate of the cooling schedule. The meaning
+	// and default value depend on the schedule.
+	CoolingRate float64
+
+	// MinTemperature is the temperature BOLTZMANN never cools below. The
+	// default value is a thousandth of the initial temperature.
+	MinTemperature float64
+
+	// TemperatureFn is a custom cooling schedule of BOLTZMANN returning the
+	// temperature of the generation. It is used instead of Cooling if set.
+	TemperatureFn func(generation uint) float64
+}
+
+// Options contains the settings an algorithm implementation is created with.
+type Options struct {
+	Params
+
+	// Elitism is the percetage of solutions that should be considered
+	// "elite" and selected implicitly.
+	Elitism uint
+
+	// Source is the source of random numbers used by the algorithm. If it
+	// is nil, a source seeded with the current time is used.
+	Source rand.Source
+}
+
+// Rand returns a random number generator using the source of the options.
+func (o Options) Rand() *rand.Rand {
+	if o.Source == nil {
+		return rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return rand.New(o.Source)
+}
+
 // NewFunc creates a new instance of the algorithm implementation this function
-// belongs to. Elitism is the percetage of solutions that should be considered
-// "elite" and selected implicitly.
-type NewFunc func(elitism uint) (Selector, error)
+// belongs to.
+type NewFunc func(opts Options) (Selector, error)
 
 // Register registers a new selection algorithm for use through the Selector
 // interface.
@@ -42,7 +169,7 @@ func Register(alg Algorithm, new NewFunc) {
 }
 
 // New creates a new instance of the selection algorithm defined by alg.
-func New(alg Algorithm, elitism uint) (Selector, error) {
+func New(alg Algorithm, opts Options) (Selector, error) {
 	syncMutex.RLock()
 	defer syncMutex.RUnlock()
 
@@ -51,7 +178,67 @@ func New(alg Algorithm, elitism uint) (Selector, error) {
 		return nil, fmt.Errorf("Algorithm not linked: %s", alg)
 	}
 
-	return newFn(elitism)
+	return newFn(opts)
+}
+
+// GenerationAware is implemented by the selection algorithms that depend on
+// the progress of the algorithm. SetGeneration is called with the number of
+// the current generation before every call of Select.
+type GenerationAware interface {
+	SetGeneration(generation uint)
+}
+
+// FitnessWeights calculates the fitness proportionate selection weight of every
+// solution of the pool into weights, which is reallocated if it is too short,
+// and returns the weights along with their total. When maximizing the weight
+// is the fitness of the solution, shifted up if there are negative fitness
+// values. When minimizing the weight is the distance of the fitness from the
+// largest fitness value in the pool, so the worst solution has a weight of
+// zero. Solutions with an infinitely bad fitness, e.g. the ones that failed to
+// be evaluated, have a weight of zero and do not affect the other weights.
+func FitnessWeights(pool solution.Pool, weights []float64) ([]float64, float64) {
+	specimens := pool.Specimens
+	if cap(weights) < len(specimens) {
+		weights = make([]float64, len(specimens))
+	}
+	weights = weights[:len(specimens)]
+	if len(specimens) == 0 {
+		return weights, 0
+	}
+
+	worst := math.Inf(-1)
+	if pool.Direction == solution.MINIMIZE {
+		worst = math.Inf(1)
+	}
+
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, sol := range specimens {
+		if sol.Fitness == worst {
+			continue
+		}
+		if sol.Fitness < min {
+			min = sol.Fitness
+		}
+		if sol.Fitness > max {
+			max = sol.Fitness
+		}
+	}
+
+	var total float64
+	for i, sol := range specimens {
+		switch {
+		case sol.Fitness == worst:
+			weights[i] = 0
+		case pool.Direction == solution.MINIMIZE:
+			weights[i] = max - sol.Fitness
+		case min < 0:
+			weights[i] = sol.Fitness - min
+		default:
+			weights[i] = sol.Fitness
+		}
+		total += weights[i]
+	}
+	return weights, total
 }
 
 // Selector is the interface for all selection algorithms in this project.
@@ -0,0 +1,93 @@
+/*
+Package sus is the stochastic universal sampling selection algorithm
+implementation.
+*/
+package sus
+
+import (
+	"math/rand"
+
+	"github.com/stiganik/gap/selection"
+	"github.com/stiganik/gap/solution"
+)
+
+func init() {
+	selection.Register(selection.SUS, New)
+}
+
+type sus struct {
+	elitism  uint
+	rnd      *rand.Rand
+	weights  []float64
+	selected []int
+}
+
+// New creates an instance of the stochastic universal sampling selection
+// algorithm.
+func New(opts selection.Options) (selection.Selector, error) {
+	return &sus{
+		elitism: opts.Elitism,
+		rnd:     opts.Rand(),
+	}, nil
+}
+
+// Select fills the non-elite part of poolB with the solutions of poolA picked
+// by evenly spaced pointers on a single spin of the roulette wheel. A solution
+// with weight w is selected either floor(w/spacing) or ceil(w/spacing) times.
+// The selected solutions are shuffled, so the combination algorithms do not
+// only pair up neighboring solutions. If all of the weights are zero the
+// solutions are selected with equal probability.
+//
+// Weights:  |---A---|-B-|-----C-----|
+// Pointers:   ^    ^    ^    ^    ^
+// Selected:   A    A    B    C    C
+func (s *sus) Select(poolA, poolB solution.Pool) error {
+	specimensA := poolA.Specimens
+	specimensB := poolB.Specimens
+	if len(specimensA) == 0 {
+		return nil
+	}
+
+	var totalWeight float64
+	s.weights, totalWeight = selection.FitnessWeights(poolA, s.weights)
+
+	elite := int((float64(s.elitism) / float64(100)) * float64(len(specimensA)))
+	if elite > len(specimensB) {
+		elite = len(specimensB)
+	}
+	for i := 0; i < elite; i++ {
+		specimensB[i].Fitness = specimensA[i].Fitness
+		copy(specimensB[i].Buf, specimensA[i].Buf)
+	}
+
+	n := len(specimensB) - elite
+	s.selected = s.selected[:0]
+	if totalWeight <= 0 {
+		for i := 0; i < n; i++ {
+			s.selected = append(s.selected, s.rnd.Intn(len(specimensA)))
+		}
+	} else {
+		spacing := totalWeight / float64(n)
+		pointer := s.rnd.Float64() * spacing
+		var sum float64
+		j := 0
+		for i := 0; i < n; i++ {
+			for j < len(specimensA)-1 && sum+s.weights[j] <= pointer {
+				sum += s.weights[j]
+				j++
+			}
+			s.selected = append(s.selected, j)
+			pointer += spacing
+		}
+		s.rnd.Shuffle(n, func(a, b int) {
+			s.selected[a], s.selected[b] = s.selected[b], s.selected[a]
+		})
+	}
+
+	for i, j := range s.selected {
+		specimensB[elite+i].Fitness = specimensA[j].Fitness
+		copy(specimensB[elite+i].Buf, specimensA[j].Buf)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+Package tournament is the tournament selection algorithm implementation.
+*/
+package tournament
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/stiganik/gap/selection"
+	"github.com/stiganik/gap/solution"
+)
+
+const (
+	defaultSize           = 2
+	defaultWinProbability = 1.0
+)
+
+func init() {
+	selection.Register(selection.TOURNAMENT, New)
+}
+
+type tournament struct {
+	elitism     uint
+	size        int
+	p           float64
+	rnd         *rand.Rand
+	contestants []int
+}
+
+// New creates an instance of the tournament selection algorithm.
+func New(opts selection.Options) (selection.Selector, error) {
+	t := &tournament{
+		elitism: opts.Elitism,
+		size:    int(opts.TournamentSize),
+		p:       opts.WinProbability,
+		rnd:     opts.Rand(),
+	}
+	if t.size == 0 {
+		t.size = defaultSize
+	}
+	if t.p == 0 {
+		t.p = defaultWinProbability
+	}
+	if t.p < 0 || t.p > 1 {
+		return nil, fmt.Errorf("Win probability out of range: %g", t.p)
+	}
+	t.contestants = make([]int, t.size)
+	return t, nil
+}
+
+// hold holds a tournament between size randomly chosen solutions of the pool,
+// chosen with replacement, and returns the index of the winner.
+func (t *tournament) hold(pool solution.Pool) int {
+	specimens := pool.Specimens
+
+	// Order the contestants from best to worst, preferring the earlier
+	// solution of the pool on ties.
+	for i := range t.contestants {
+		c := t.rnd.Intn(len(specimens))
+		j := i
+		for ; j > 0; j-- {
+			prev := t.contestants[j-1]
+			if !pool.Direction.Better(specimens[c].Fitness, specimens[prev].Fitness) &&
+				(specimens[c].Fitness != specimens[prev].Fitness || c >= prev) {
+				break
+			}
+			t.contestants[j] = prev
+		}
+		t.contestants[j] = c
+	}
+
+	if t.p == 1 {
+		return t.contestants[0]
+	}
+	for _, c := range t.contestants[:len(t.contestants)-1] {
+		if t.rnd.Float64() < t.p {
+			return c
+		}
+	}
+	return t.contestants[len(t.contestants)-1]
+}
+
+// Select fills poolB with the winners of tournaments held between the
+// solutions of poolA. The elite solutions of poolA are copied first, which
+// requires poolA to be sorted from best to worst.
+func (t *tournament) Select(poolA, poolB solution.Pool) error {
+	specimensA := poolA.Specimens
+	specimensB := poolB.Specimens
+	if len(specimensA) == 0 {
+		return nil
+	}
+
+	elite := uint((float64(t.elitism) / float64(100)) * float64(len(specimensA)))
+	for i := range specimensB {
+		if uint(i) < elite {
+			specimensB[i].Fitness = specimensA[i].Fitness
+			copy(specimensB[i].Buf, specimensA[i].Buf)
+			continue
+		}
+
+		el := specimensA[t.hold(poolA)]
+		specimensB[i].Fitness = el.Fitness
+		copy(specimensB[i].Buf, el.Buf)
+	}
+
+	return nil
+}
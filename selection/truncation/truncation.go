@@ -0,0 +1,96 @@
+/*
+Package truncation is the truncation selection algorithm implementation.
+*/
+package truncation
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/stiganik/gap/selection"
+	"github.com/stiganik/gap/solution"
+)
+
+const defaultTruncation = 50
+
+func init() {
+	selection.Register(selection.TRUNCATION, New)
+}
+
+type truncation struct {
+	elitism    uint
+	truncation uint
+	rnd        *rand.Rand
+	selected   []int
+}
+
+// New creates an instance of the truncation selection algorithm.
+func New(opts selection.Options) (selection.Selector, error) {
+	t := &truncation{
+		elitism:    opts.Elitism,
+		truncation: opts.Truncation,
+		rnd:        opts.Rand(),
+	}
+	if t.truncation == 0 {
+		t.truncation = defaultTruncation
+	}
+	if t.truncation > 100 {
+		return nil, fmt.Errorf("Truncation out of range: %d", t.truncation)
+	}
+	return t, nil
+}
+
+// Select fills the non-elite part of poolB with copies of the best solutions
+// of poolA, which requires poolA to be sorted from best to worst. Every parent
+// is copied the same amount of times. If the amount of parents does not divide
+// the amount of solutions to fill, the remaining solutions are copies of
+// distinct randomly chosen parents. The copies are shuffled, so the combination
+// algorithms do not only pair up copies of the same parent.
+//
+// Pool A: A B C D E F G H
+// T = 25%, parents A B
+// Pool B: B A A B B A B A
+func (t *truncation) Select(poolA, poolB solution.Pool) error {
+	specimensA := poolA.Specimens
+	specimensB := poolB.Specimens
+	if len(specimensA) == 0 {
+		return nil
+	}
+
+	elite := int((float64(t.elitism) / float64(100)) * float64(len(specimensA)))
+	if elite > len(specimensB) {
+		elite = len(specimensB)
+	}
+	for i := 0; i < elite; i++ {
+		specimensB[i].Fitness = specimensA[i].Fitness
+		copy(specimensB[i].Buf, specimensA[i].Buf)
+	}
+
+	parents := int(math.Ceil(float64(t.truncation) / float64(100) * float64(len(specimensA))))
+	if parents < 1 {
+		parents = 1
+	}
+
+	// Hand out the full rounds of copies first and the remaining copies
+	// to a random subset of the parents.
+	n := len(specimensB) - elite
+	s := t.selected[:0]
+	for i := 0; i < n-n%parents; i++ {
+		s = append(s, i%parents)
+	}
+	for _, p := range t.rnd.Perm(parents)[:n%parents] {
+		s = append(s, p)
+	}
+	t.rnd.Shuffle(len(s), func(a, b int) {
+		s[a], s[b] = s[b], s[a]
+	})
+	t.selected = s
+
+	for i, j := range s {
+		specimensB[elite+i].Fitness = specimensA[j].Fitness
+		copy(specimensB[elite+i].Buf, specimensA[j].Buf)
+	}
+
+	return nil
+}
@@ -5,14 +5,35 @@ package.
 package solution
 
 import (
+	"fmt"
 	"math/rand"
 	"sort"
 	"time"
 )
 
+// Direction defines whether higher or lower fitness values are better.
+type Direction uint8
+
+const (
+	// MAXIMIZE makes higher fitness values better. This is the default.
+	MAXIMIZE Direction = iota
+
+	// MINIMIZE makes lower fitness values better, e.g. for minimizing the
+	// cost of a solution.
+	MINIMIZE
+)
+
+// Better reports whether the fitness a is better than the fitness b.
+func (d Direction) Better(a, b float64) bool {
+	if d == MINIMIZE {
+		return a < b
+	}
+	return a > b
+}
+
 // Specimen is a single solution for the genetic algorithm.
 type Specimen struct {
-	Fitness uint
+	Fitness float64
 	Buf     []byte
 }
 
@@ -31,10 +52,20 @@ type Specimens []Specimen
 
 // Pool is a wrapper for the Specimens array with some additional functionality
 // and problem information.
+//
+// The bits of a solution are ordered from the least significant bit of the
+// first byte onwards, i.e. bit i of a solution is bit i%8 of byte i/8, where
+// bit 0 is the least significant one. If SpecimenBitSize is not a multiple of 8
+// the remaining high bits of the last byte are padding. The padding bits are
+// always zero and must not be set by any algorithm.
 type Pool struct {
 	SpecimenBitSize  uint
 	SpecimenByteSize uint
 	Specimens        Specimens
+
+	// Direction is the optimization direction of the problem. Once sorted
+	// with Sort the specimens of the pool are ordered from best to worst.
+	Direction Direction
 }
 
 // Len is the number of elements in the collection.
@@ -64,6 +95,16 @@ func (s Specimens) SortDesc() {
 	sort.Sort(sort.Reverse(s))
 }
 
+// Sort sorts the elements in the collection from best to worst according to
+// the direction d.
+func (s Specimens) Sort(d Direction) {
+	if d == MINIMIZE {
+		s.SortAsc()
+	} else {
+		s.SortDesc()
+	}
+}
+
 // SortAsc sorts the elements in the collection in an ascending order as defined
 // by Less(i, j int).
 func (s Specimens) SortAsc() {
@@ -89,9 +130,54 @@ func NewPool(poolSize, bitSize uint) Pool {
 	return p
 }
 
+// paddingMask returns the mask of the padding bits in the last byte of a
+// solution of bitSize bits.
+func paddingMask(bitSize uint) byte {
+	if bitSize%8 == 0 {
+		return 0
+	}
+	return ^byte(1<<(bitSize%8) - 1)
+}
+
+// ClearPadding clears the padding bits of the solution buf of bitSize bits.
+func ClearPadding(buf []byte, bitSize uint) {
+	if len(buf) > 0 {
+		buf[len(buf)-1] &^= paddingMask(bitSize)
+	}
+}
+
+// HasPadding reports whether any of the padding bits of the solution buf of
+// bitSize bits are set.
+func HasPadding(buf []byte, bitSize uint) bool {
+	return len(buf) > 0 && buf[len(buf)-1]&paddingMask(bitSize) != 0
+}
+
+// ClearPadding clears the padding bits of every solution in the pool.
+func (p Pool) ClearPadding() {
+	for i := range p.Specimens {
+		ClearPadding(p.Specimens[i].Buf, p.SpecimenBitSize)
+	}
+}
+
+// CheckPadding returns an error if the padding bits of any solution in the pool
+// are set.
+func (p Pool) CheckPadding() error {
+	for i := range p.Specimens {
+		if HasPadding(p.Specimens[i].Buf, p.SpecimenBitSize) {
+			return fmt.Errorf("Padding bits set in solution %d", i)
+		}
+	}
+	return nil
+}
+
 // Seed seeds the pool with random values.
 func (p Pool) Seed() error {
-	src := rand.NewSource(time.Now().UnixNano())
+	return p.SeedFrom(rand.NewSource(time.Now().UnixNano()))
+}
+
+// SeedFrom seeds the pool with random values read from src. The padding bits
+// are left unset.
+func (p Pool) SeedFrom(src rand.Source) error {
 	r := rand.New(src)
 
 	for i := range p.Specimens {
@@ -100,5 +186,6 @@ func (p Pool) Seed() error {
 		}
 	}
 
+	p.ClearPadding()
 	return nil
 }
@@ -0,0 +1,141 @@
+package gap
+
+import (
+	"bytes"
+
+	"github.com/stiganik/gap/solution"
+)
+
+// Mode defines how the solution pool is replaced by new solutions.
+type Mode uint
+
+const (
+	// GENERATIONAL replaces the whole solution pool with a new generation
+	// of solutions at once. This is the default mode.
+	GENERATIONAL Mode = iota
+
+	// STEADY_STATE creates and evaluates a few offspring at a time and
+	// inserts them into the solution pool, replacing existing solutions
+	// as defined by the replacement strategy of the algorithm. Every time
+	// as many offspring as there are solutions in the pool have been
+	// created, a generation is considered complete.
+	STEADY_STATE
+)
+
+// Replacement defines which solutions the offspring replace in the steady
+// state mode.
+type Replacement uint
+
+const (
+	// REPLACE_WORST replaces the worst solutions of the pool. This is the
+	// default replacement strategy.
+	REPLACE_WORST Replacement = iota
+
+	// REPLACE_RANDOM replaces randomly chosen solutions of the pool.
+	REPLACE_RANDOM
+
+	// REPLACE_PARENT replaces the parent the offspring was bred from. If
+	// the parent has already been replaced, the worst solution is
+	// replaced instead.
+	REPLACE_PARENT
+)
+
+// breedSteady breeds, evaluates and inserts a generation worth of offspring
+// into the pool in the steady state mode. The pool is kept evaluated and sorted
+// from best to worst at all times, except for the initial pool which is
+// evaluated as generation 0. If an offspring reaches the fitness goal it is
+// returned in found. stop is set if the goal was reached in the middle of the
+// generation.
+func (r *run) breedSteady(g *Goal) (found *solution.Specimen, stop bool, err error) {
+	for bred := uint(0); bred < r.a.SolutionPoolSize; bred += uint(len(r.offspring.Specimens)) {
+		if g.checkTime() {
+			return nil, true, nil
+		}
+
+		var i int
+		if i, stop, err = r.step(g); err != nil {
+			return
+		}
+		if i >= 0 {
+			return &r.offspring.Specimens[i], true, nil
+		}
+		if stop {
+			g.checkEvaluations(r.totalEvaluations())
+			return
+		}
+	}
+	return nil, false, nil
+}
+
+// step breeds and evaluates one batch of offspring and inserts them into the
+// pool. The index of the offspring that reached the fitness goal is returned,
+// or -1 if none of them did. stop is set if the evaluation was cut short.
+func (r *run) step(g *Goal) (found int, stop bool, err error) {
+	a := r.a
+	pool := r.pools[r.cur]
+	offspring := r.offspring.Specimens
+
+	if err = r.selectInto(pool, r.offspring); err != nil {
+		return -1, false, err
+	}
+	if a.Replacement == REPLACE_PARENT {
+		for i := range offspring {
+			copy(r.parents[i], offspring[i].Buf)
+		}
+	}
+
+	if err = r.combine(r.offspring); err != nil {
+		return -1, false, err
+	}
+
+	found, finished, calls, err := r.evaluate(offspring, g)
+	r.evaluations += calls
+	if err != nil {
+		return -1, true, err
+	}
+	if found >= 0 {
+		return found, true, nil
+	}
+	if g.checkTime() {
+		r.rankPartial(finished)
+		return -1, true, nil
+	}
+
+	for i := range finished {
+		target := r.replacementTarget(pool.Specimens, i)
+		pool.Specimens[target].Copy(finished[i])
+		reposition(pool.Specimens, target, a.Direction)
+		r.improve(finished[i])
+	}
+
+	return -1, len(finished) < len(offspring), nil
+}
+
+// replacementTarget returns the index of the specimen the i-th offspring
+// replaces.
+func (r *run) replacementTarget(specimens solution.Specimens, i int) int {
+	switch r.a.Replacement {
+	case REPLACE_RANDOM:
+		return r.rnd.Intn(len(specimens))
+	case REPLACE_PARENT:
+		for j := range specimens {
+			if bytes.Equal(specimens[j].Buf, r.parents[i]) {
+				return j
+			}
+		}
+	}
+	return len(specimens) - 1
+}
+
+// reposition moves the specimen at index i of the otherwise sorted specimens
+// to its sorted position.
+func reposition(specimens solution.Specimens, i int, d solution.Direction) {
+	for i > 0 && d.Better(specimens[i].Fitness, specimens[i-1].Fitness) {
+		specimens.Swap(i, i-1)
+		i--
+	}
+	for i < len(specimens)-1 && d.Better(specimens[i+1].Fitness, specimens[i].Fitness) {
+		specimens.Swap(i, i+1)
+		i++
+	}
+}
@@ -0,0 +1,166 @@
+package gap
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// Terminator decides when the algorithm should stop. Custom end conditions can
+// be added to a goal by implementing this interface.
+type Terminator interface {
+	// Terminate is called with the statistics of every generation once it
+	// has been evaluated and ranked. It reports whether the algorithm
+	// should stop and the reason for stopping.
+	Terminate(info GenerationInfo) (stop bool, reason string)
+}
+
+// TerminatorFunc is an adapter to allow the use of ordinary functions as
+// terminators.
+type TerminatorFunc func(info GenerationInfo) (bool, string)
+
+// Terminate calls f(info).
+func (f TerminatorFunc) Terminate(info GenerationInfo) (bool, string) {
+	return f(info)
+}
+
+// Reasons reported by the built-in terminators.
+const (
+	ReasonTime        = "time limit reached"
+	ReasonGeneration  = "generation limit reached"
+	ReasonFitness     = "fitness goal reached"
+	ReasonStagnation  = "fitness stagnated"
+	ReasonEvaluations = "evaluation limit reached"
+	ReasonCanceled    = "canceled"
+)
+
+// TimeLimit returns a terminator that stops the algorithm once it has run for
+// at least d.
+func TimeLimit(d time.Duration) Terminator {
+	return TerminatorFunc(func(info GenerationInfo) (bool, string) {
+		return info.ElapsedTime >= d, ReasonTime
+	})
+}
+
+// GenerationLimit returns a terminator that stops the algorithm once n
+// generations have been completed.
+func GenerationLimit(n uint) Terminator {
+	return TerminatorFunc(func(info GenerationInfo) (bool, string) {
+		return info.Generation+1 >= n, ReasonGeneration
+	})
+}
+
+// FitnessLimit returns a terminator that stops the algorithm once the best
+// fitness of a generation is at least as good as f.
+func FitnessLimit(f float64) Terminator {
+	return TerminatorFunc(func(info GenerationInfo) (bool, string) {
+		return !info.Direction.Better(f, info.Best), ReasonFitness
+	})
+}
+
+// EvaluationLimit returns a terminator that stops the algorithm once the
+// fitness function has been called at least n times.
+func EvaluationLimit(n uint64) Terminator {
+	return TerminatorFunc(func(info GenerationInfo) (bool, string) {
+		return info.Evaluations >= n, ReasonEvaluations
+	})
+}
+
+type stagnation struct {
+	n       uint
+	window  uint
+	epsilon float64
+
+	seen  bool
+	best  float64
+	gen   uint
+	means []float64
+}
+
+// stagnationState is the state of a stagnation terminator saved in
+// checkpoints, so a resumed run stops in the same generation as an
+// uninterrupted one.
+type stagnationState struct {
+	Seen  bool
+	Best  float64
+	Gen   uint
+	Means []float64
+}
+
+// Stagnation returns a terminator that stops the algorithm after the best
+// fitness has not improved for n generations, or after the mean fitness has
+// changed less than epsilon over window generations. A zero n or window
+// disables the corresponding check, the window check never stops the
+// algorithm unless epsilon is positive. The terminator keeps track of the
+// previous generations and must not be shared between runs. Unlike the
+// STAGNATION goal, its state is not saved in checkpoints.
+func Stagnation(n, window uint, epsilon float64) Terminator {
+	return &stagnation{n: n, window: window, epsilon: epsilon}
+}
+
+func (s *stagnation) Terminate(info GenerationInfo) (bool, string) {
+	stop := false
+	if !s.seen || info.Direction.Better(info.Best, s.best) {
+		s.seen = true
+		s.best = info.Best
+		s.gen = info.Generation
+	}
+	if s.n > 0 && info.Generation-s.gen >= s.n {
+		stop = true
+	}
+
+	if s.window > 0 {
+		s.means = append(s.means, info.Mean)
+		if uint(len(s.means)) > s.window {
+			oldest := s.means[0]
+			s.means = s.means[1:]
+			if math.Abs(info.Mean-oldest) < s.epsilon {
+				stop = true
+			}
+		}
+	}
+
+	return stop, ReasonStagnation
+}
+
+func (s *stagnation) state() stagnationState {
+	return stagnationState{s.seen, s.best, s.gen, s.means}
+}
+
+func (s *stagnation) restore(st stagnationState) {
+	s.seen, s.best, s.gen, s.means = st.Seen, st.Best, st.Gen, st.Means
+}
+
+// Or returns a terminator that stops the algorithm once any of the terminators
+// does. All of the terminators are consulted every generation. The reason of
+// the first terminator to stop is reported.
+func Or(ts ...Terminator) Terminator {
+	return TerminatorFunc(func(info GenerationInfo) (bool, string) {
+		stop, reason := false, ""
+		for _, t := range ts {
+			if s, r := t.Terminate(info); s && !stop {
+				stop, reason = true, r
+			}
+		}
+		return stop, reason
+	})
+}
+
+// And returns a terminator that stops the algorithm once all of the
+// terminators do in the same generation. All of the terminators are consulted
+// every generation. The reasons of the terminators are joined together.
+func And(ts ...Terminator) Terminator {
+	return TerminatorFunc(func(info GenerationInfo) (bool, string) {
+		stop := len(ts) > 0
+		reasons := make([]string, 0, len(ts))
+		for _, t := range ts {
+			s, r := t.Terminate(info)
+			stop = stop && s
+			reasons = append(reasons, r)
+		}
+		if !stop {
+			return false, ""
+		}
+		return true, strings.Join(reasons, " and ")
+	})
+}